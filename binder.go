@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"reflect"
 	"strconv"
@@ -21,15 +22,33 @@ type Binder struct {
 	//
 	// Request:
 	//   url?id=123&ol[0]=1&ol[1]=2&ul[]=str&ul[]=array&user.Name=rob
+	//       &order.Items[0].Options[color]=red
 	//
 	// Action:
-	//   Example.Action(id int, ol []int, ul []string, user User)
+	//   Example.Action(id int, ol []int, ul []string, user User, order Order)
 	//
 	// Calls:
 	//   Bind(params, "id", int): 123
 	//   Bind(params, "ol", []int): {1, 2}
 	//   Bind(params, "ul", []string): {"str", "array"}
 	//   Bind(params, "user", User): User{Name:"rob"}
+	//   Bind(params, "order", Order): Order{Items:[]Item{{Options:{"color":"red"}}}}
+	//
+	// Paths may be nested arbitrarily. Struct fields are matched by name,
+	// falling back to a case-insensitive match, and fields promoted from
+	// embedded structs may be addressed directly.
+	//
+	// Struct fields may be tagged to bind from a single request source instead
+	// of the merged Params.Values. The tag is `from:"source[,name]"`, where
+	// source is one of path, fixed, query, form, header or cookie. The name
+	// defaults to the field name.
+	//
+	//   type ShowRequest struct {
+	//     Id      int    `from:"path,id"`
+	//     Page    int    `from:"query,page"`
+	//     Token   string `from:"header,X-Api-Token"`
+	//     Visitor string `from:"cookie,visitor"`
+	//   }
 	//
 	// Note that only exported struct fields may be bound.
 	Bind func(params *Params, name string, typ reflect.Type) reflect.Value
@@ -51,8 +70,10 @@ func ValueBinder(f func(value string, typ reflect.Type) reflect.Value) func(*Par
 }
 
 const (
-	DEFAULT_DATE_FORMAT     = "2006-01-02"
-	DEFAULT_DATETIME_FORMAT = "2006-01-02 15:04"
+	DEFAULT_DATE_FORMAT       = "2006-01-02"
+	DEFAULT_DATETIME_FORMAT   = "2006-01-02 15:04"
+	DEFAULT_MAX_BIND_INDEX    = 10000
+	DEFAULT_MAX_BIND_ELEMENTS = 100000
 )
 
 var (
@@ -68,6 +89,19 @@ var (
 	DateFormat     string
 	DateTimeFormat string
 
+	// MaxBindIndex is the largest slice index accepted from request params.
+	// Larger indexes are dropped, so that a request like "ids[100000000]=1"
+	// can not allocate a huge slice.
+	// It may be specified in config as "binder.max_index".
+	MaxBindIndex = DEFAULT_MAX_BIND_INDEX
+
+	// MaxBindElements is the largest number of slice and map elements bound
+	// from request params by a call to Bind, all nested slices and maps
+	// included, so that a request like "a[0][10000]...a[10000][10000]" can
+	// not allocate them all either.
+	// It may be specified in config as "binder.max_elements".
+	MaxBindElements = DEFAULT_MAX_BIND_ELEMENTS
+
 	IntBinder = Binder{
 		Bind: ValueBinder(func(val string, typ reflect.Type) reflect.Value {
 			if len(val) == 0 {
@@ -155,10 +189,16 @@ var (
 
 	PointerBinder = Binder{
 		Bind: func(params *Params, name string, typ reflect.Type) reflect.Value {
-			return Bind(params, name, typ.Elem()).Addr()
+			pValue := reflect.New(typ.Elem())
+			pValue.Elem().Set(Bind(params, name, typ.Elem()))
+			return pValue
 		},
 		Unbind: func(output map[string]string, name string, val interface{}) {
-			Unbind(output, name, reflect.ValueOf(val).Elem().Interface())
+			pValue := reflect.ValueOf(val)
+			if pValue.IsNil() {
+				return
+			}
+			Unbind(output, name, pValue.Elem().Interface())
 		},
 	}
 
@@ -227,6 +267,8 @@ func init() {
 		DateTimeFormat = Config.StringDefault("format.datetime", DEFAULT_DATETIME_FORMAT)
 		DateFormat = Config.StringDefault("format.date", DEFAULT_DATE_FORMAT)
 		TimeFormats = append(TimeFormats, DateTimeFormat, DateFormat)
+		MaxBindIndex = Config.IntDefault("binder.max_index", DEFAULT_MAX_BIND_INDEX)
+		MaxBindElements = Config.IntDefault("binder.max_elements", DEFAULT_MAX_BIND_ELEMENTS)
 	})
 }
 
@@ -240,11 +282,14 @@ type sliceValue struct {
 // elements, and then sets them to their appropriate location in the slice.
 // If elements are provided without an explicit index, they are added (in
 // unspecified order) to the end of the slice.
+// Indexes that are malformed or greater than MaxBindIndex are ignored, and
+// the slice is not bound if it would exceed MaxBindElements.
 func bindSlice(params *Params, name string, typ reflect.Type) reflect.Value {
 	// Collect an array of slice elements with their indexes (and the max index).
 	maxIndex := -1
 	numNoIndex := 0
 	sliceValues := []sliceValue{}
+	boundIndexes := make(map[int]bool)
 
 	// Factor out the common slice logic (between form values and files).
 	processElement := func(key string, vals []string, files []*multipart.FileHeader) {
@@ -253,15 +298,26 @@ func bindSlice(params *Params, name string, typ reflect.Type) reflect.Value {
 		}
 
 		// Extract the index, and the index where a sub-key starts. (e.g. field[0].subkey)
-		index := -1
 		leftBracket, rightBracket := len(name), strings.Index(key[len(name):], "]")+len(name)
-		if rightBracket > leftBracket+1 {
-			index, _ = strconv.Atoi(key[leftBracket+1 : rightBracket])
+		if rightBracket < leftBracket {
+			return
 		}
 		subKeyIndex := rightBracket + 1
 
 		// Handle the indexed case.
-		if index > -1 {
+		if rightBracket > leftBracket+1 {
+			index, err := strconv.Atoi(key[leftBracket+1 : rightBracket])
+			if err != nil || index < 0 || index > MaxBindIndex {
+				WARN.Println("W: bindSlice: Invalid or too large index:", key)
+				return
+			}
+
+			// Every sub-key of an element binds the whole element, so only do it once.
+			if boundIndexes[index] {
+				return
+			}
+			boundIndexes[index] = true
+
 			if index > maxIndex {
 				maxIndex = index
 			}
@@ -297,6 +353,10 @@ func bindSlice(params *Params, name string, typ reflect.Type) reflect.Value {
 		processElement(key, nil, fileHeaders)
 	}
 
+	if !params.allocBindElements(maxIndex + 1 + numNoIndex) {
+		WARN.Println("W: bindSlice: Too many elements:", name)
+		return reflect.Zero(typ)
+	}
 	resultArray := reflect.MakeSlice(typ, maxIndex+1, maxIndex+1+numNoIndex)
 	for _, sv := range sliceValues {
 		if sv.index != -1 {
@@ -319,6 +379,90 @@ func nextKey(key string) string {
 	return key[:fieldLen]
 }
 
+// splitBindPath breaks a param name into the elements of its path.
+// e.g. order.Items[3].Options[color] => "order", "Items", "3", "Options", "color"
+func splitBindPath(name string) []string {
+	var path []string
+	for len(name) > 0 {
+		switch name[0] {
+		case '.':
+			name = name[1:]
+		case '[':
+			end := strings.Index(name, "]")
+			if end == -1 {
+				return append(path, name[1:])
+			}
+			path = append(path, name[1:end])
+			name = name[end+1:]
+		default:
+			key := nextKey(name)
+			path = append(path, key)
+			name = name[len(key):]
+		}
+	}
+	return path
+}
+
+// fieldByName returns the named field of the struct value, including fields
+// promoted from embedded structs.  If no field has the exact name, a
+// case-insensitive match is tried.  Nil embedded pointers along the way are
+// allocated if the struct is settable.
+// Returns an invalid Value if the field can not be found or reached.
+func fieldByName(val reflect.Value, name string) reflect.Value {
+	structField, ok := structFieldByName(val.Type(), name)
+	if !ok {
+		return reflect.Value{}
+	}
+
+	for i, index := range structField.Index {
+		if i > 0 && val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				if !val.CanSet() {
+					return reflect.Value{}
+				}
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+		val = val.Field(index)
+	}
+	return val
+}
+
+// lookupFieldByName returns the named field of the struct value, as found by
+// fieldByName, without allocating nil embedded pointers, so that reading a
+// field does not modify the struct.
+// Returns an invalid Value if the field can not be found, or is promoted from
+// a nil embedded pointer.
+func lookupFieldByName(val reflect.Value, name string) reflect.Value {
+	structField, ok := structFieldByName(val.Type(), name)
+	if !ok {
+		return reflect.Value{}
+	}
+
+	for i, index := range structField.Index {
+		if i > 0 && val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return reflect.Value{}
+			}
+			val = val.Elem()
+		}
+		val = val.Field(index)
+	}
+	return val
+}
+
+// structFieldByName returns the named field of the struct type, as found by
+// fieldByName.
+func structFieldByName(typ reflect.Type, name string) (reflect.StructField, bool) {
+	if structField, ok := typ.FieldByName(name); ok {
+		return structField, true
+	}
+	return typ.FieldByNameFunc(func(fieldName string) bool {
+		return strings.EqualFold(fieldName, name)
+	})
+}
+
 func unbindSlice(output map[string]string, name string, val interface{}) {
 	v := reflect.ValueOf(val)
 	for i := 0; i < v.Len(); i++ {
@@ -329,6 +473,19 @@ func unbindSlice(output map[string]string, name string, val interface{}) {
 func bindStruct(params *Params, name string, typ reflect.Type) reflect.Value {
 	result := reflect.New(typ).Elem()
 	fieldValues := make(map[string]reflect.Value)
+
+	// Bind the fields tagged with an explicit source first.
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if !isSourcedField(structField) {
+			continue
+		}
+
+		boundVal := bindFrom(params, structField.Tag.Get("from"), structField.Name, structField.Type)
+		result.Field(i).Set(boundVal)
+		fieldValues[structField.Name] = boundVal
+	}
+
 	for key, _ := range params.Values {
 		if !strings.HasPrefix(key, name+".") {
 			continue
@@ -340,9 +497,14 @@ func bindStruct(params *Params, name string, typ reflect.Type) reflect.Value {
 		fieldName := nextKey(suffix)
 		fieldLen := len(fieldName)
 
+		// The fields tagged with a source are only bound from it.
+		if structField, ok := structFieldByName(typ, fieldName); ok && isSourcedField(structField) {
+			continue
+		}
+
 		if _, ok := fieldValues[fieldName]; !ok {
 			// Time to bind this field.  Get it and make sure we can set it.
-			fieldValue := result.FieldByName(fieldName)
+			fieldValue := fieldByName(result, fieldName)
 			if !fieldValue.IsValid() {
 				WARN.Println("W: bindStruct: Field not found:", fieldName)
 				continue
@@ -360,6 +522,36 @@ func bindStruct(params *Params, name string, typ reflect.Type) reflect.Value {
 	return result
 }
 
+// isSourcedField returns whether the field is an exported field of the struct
+// itself, tagged with a param source.
+func isSourcedField(structField reflect.StructField) bool {
+	return len(structField.Index) == 1 && structField.PkgPath == "" && structField.Tag.Get("from") != ""
+}
+
+// bindFrom binds a value of the given type from the single param source named
+// by a `from:"source[,name]"` struct tag.
+func bindFrom(params *Params, from, fieldName string, typ reflect.Type) reflect.Value {
+	source, key := from, fieldName
+	if comma := strings.Index(from, ","); comma != -1 {
+		source, key = from[:comma], from[comma+1:]
+	}
+	source = strings.ToLower(strings.TrimSpace(source))
+
+	values := params.Source(source)
+	if values == nil {
+		return reflect.Zero(typ)
+	}
+	if source == "header" {
+		key = http.CanonicalHeaderKey(key)
+	}
+
+	sourceParams := &Params{Values: values, bindElements: params.bindElements}
+	if source == "form" {
+		sourceParams.Files = params.Files
+	}
+	return Bind(sourceParams, key, typ)
+}
+
 func unbindStruct(output map[string]string, name string, iface interface{}) {
 	val := reflect.ValueOf(iface)
 	typ := val.Type()
@@ -368,9 +560,27 @@ func unbindStruct(output map[string]string, name string, iface interface{}) {
 		fieldValue := val.Field(i)
 
 		// PkgPath is specified to be empty exactly for exported fields.
-		if structField.PkgPath == "" {
-			Unbind(output, fmt.Sprintf("%s.%s", name, structField.Name), fieldValue.Interface())
+		if structField.PkgPath != "" {
+			continue
 		}
+
+		// Fields of embedded structs are promoted, so they are unbound as if
+		// they were declared on the outer struct.
+		if structField.Anonymous {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				unbindStruct(output, name, embedded.Interface())
+				continue
+			}
+		}
+
+		Unbind(output, fmt.Sprintf("%s.%s", name, structField.Name), fieldValue.Interface())
 	}
 }
 
@@ -442,19 +652,42 @@ func bindReadSeeker(params *Params, name string, typ reflect.Type) reflect.Value
 
 // bindMap converts parameters using map syntax into the corresponding map. e.g.:
 //   params["a[5]"]=foo, name="a", typ=map[int]string => map[int]string{5: "foo"}
+// Map values may themselves be nested, e.g.:
+//   params["a[x][]"]=foo, name="a", typ=map[string][]string => map[string][]string{"x": {"foo"}}
+// Keys beyond MaxBindElements are ignored.
 func bindMap(params *Params, name string, typ reflect.Type) reflect.Value {
 	var (
 		result    = reflect.MakeMap(typ)
 		keyType   = typ.Key()
 		valueType = typ.Elem()
+		boundKeys = make(map[string]bool)
 	)
-	for paramName, values := range params.Values {
-		if !strings.HasPrefix(paramName, name+"[") || paramName[len(paramName)-1] != ']' {
+	for paramName, _ := range params.Values {
+		if !strings.HasPrefix(paramName, name+"[") {
 			continue
 		}
 
-		key := paramName[len(name)+1 : len(paramName)-1]
-		result.SetMapIndex(BindValue(key, keyType), BindValue(values[0], valueType))
+		// Extract the key, and make sure that anything after it is a sub-key.
+		suffix := paramName[len(name)+1:]
+		rightBracket := strings.Index(suffix, "]")
+		if rightBracket == -1 {
+			continue
+		}
+		if rest := suffix[rightBracket+1:]; rest != "" && rest[0] != '.' && rest[0] != '[' {
+			continue
+		}
+
+		key := suffix[:rightBracket]
+		if boundKeys[key] {
+			continue
+		}
+		boundKeys[key] = true
+		if !params.allocBindElements(1) {
+			WARN.Println("W: bindMap: Too many elements:", name)
+			break
+		}
+
+		result.SetMapIndex(BindValue(key, keyType), Bind(params, name+"["+key+"]", valueType))
 	}
 	return result
 }
@@ -471,12 +704,37 @@ func unbindMap(output map[string]string, name string, iface interface{}) {
 // from one or more values from Params.
 // Returns the zero value of the type upon any sort of failure.
 func Bind(params *Params, name string, typ reflect.Type) reflect.Value {
+	// Count the elements bound by the nested calls against the same budget.
+	if params.bindElements == nil {
+		var elements int
+		params.bindElements = &elements
+		defer func() { params.bindElements = nil }()
+	}
+
 	if binder, found := binderForType(typ); found {
-		return binder.Bind(params, name, typ)
+		value := binder.Bind(params, name, typ)
+
+		// Kind binders may return the underlying type for named types,
+		// e.g. string for "type Color string".
+		if value.IsValid() && value.Type() != typ && typ.Kind() != reflect.Interface &&
+			value.Type().ConvertibleTo(typ) {
+			value = value.Convert(typ)
+		}
+		return value
 	}
 	return reflect.Zero(typ)
 }
 
+// allocBindElements counts n more slice or map elements against the budget of
+// the current Bind call, and returns false if it is exceeded.
+func (p *Params) allocBindElements(n int) bool {
+	if p.bindElements == nil {
+		return true
+	}
+	*p.bindElements += n
+	return *p.bindElements <= MaxBindElements
+}
+
 func BindValue(val string, typ reflect.Type) reflect.Value {
 	return Bind(&Params{Values: map[string][]string{"": {val}}}, "", typ)
 }
@@ -486,6 +744,9 @@ func BindFile(fileHeader *multipart.FileHeader, typ reflect.Type) reflect.Value
 }
 
 func Unbind(output map[string]string, name string, val interface{}) {
+	if val == nil {
+		return
+	}
 	if binder, found := binderForType(reflect.TypeOf(val)); found {
 		if binder.Unbind != nil {
 			binder.Unbind(output, name, val)
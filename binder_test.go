@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/url"
 	"os"
 	"reflect"
 	"sort"
@@ -230,6 +232,149 @@ func TestBinder(t *testing.T) {
 	}
 }
 
+type SourcedRequest struct {
+	Id      int    `from:"path,id"`
+	Page    int    `from:"query,page"`
+	Token   string `from:"header,x-api-token"`
+	Visitor string `from:"cookie,visitor"`
+	Name    string
+}
+
+func TestBindFromSource(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://localhost/users/5?id=9&page=2&req.Name=rob", nil)
+	req.Header.Set("X-Api-Token", "abc")
+	req.AddCookie(&http.Cookie{Name: "visitor", Value: "v1"})
+
+	params := &Params{Route: url.Values{"id": {"5"}}}
+	ParseParams(params, NewRequest(req))
+
+	actual := Bind(params, "req", reflect.TypeOf(SourcedRequest{}))
+	expected := SourcedRequest{Id: 5, Page: 2, Token: "abc", Visitor: "v1", Name: "rob"}
+	valEq(t, "req", actual, reflect.ValueOf(expected))
+
+	// A sourced field may not be overridden from another source.
+	req, _ = http.NewRequest("GET", "http://localhost/users/5?req.id=9&req.Page=7&req.Name=rob", nil)
+	params = &Params{Route: url.Values{"id": {"5"}}}
+	ParseParams(params, NewRequest(req))
+	actual = Bind(params, "req", reflect.TypeOf(SourcedRequest{}))
+	valEq(t, "req", actual, reflect.ValueOf(SourcedRequest{Id: 5, Name: "rob"}))
+
+	// Headers and cookies must not leak into the merged values.
+	if _, ok := params.Values["X-Api-Token"]; ok {
+		t.Errorf("Header found in Params.Values")
+	}
+	if _, ok := params.Values["visitor"]; ok {
+		t.Errorf("Cookie found in Params.Values")
+	}
+}
+
+type Order struct {
+	Customer
+	Items []Item
+	Notes *string
+	Tags  map[string][]string
+}
+
+type Customer struct {
+	Email string
+}
+
+type Item struct {
+	Sku     string
+	Options map[string]string
+}
+
+func TestBindNested(t *testing.T) {
+	params := &Params{Values: url.Values{
+		"order.Email":                   {"rob@example.com"},
+		"order.items[0].Sku":            {"a1"},
+		"order.items[2].Sku":            {"c3"},
+		"order.items[2].Options[color]": {"red"},
+		"order.items[2].Options[size]":  {"L"},
+		"order.Notes":                   {"fragile"},
+		"order.Tags[x][]":               {"1", "2"},
+		"order.Tags[y][0]":              {"3"},
+	}}
+
+	notes := "fragile"
+	expected := Order{
+		Customer: Customer{Email: "rob@example.com"},
+		Items: []Item{
+			{Sku: "a1", Options: map[string]string{}},
+			{},
+			{Sku: "c3", Options: map[string]string{"color": "red", "size": "L"}},
+		},
+		Notes: &notes,
+		Tags:  map[string][]string{"x": {"1", "2"}, "y": {"3"}},
+	}
+
+	actual := Bind(params, "order", reflect.TypeOf(Order{})).Interface().(Order)
+	if actual.Email != expected.Email || len(actual.Items) != len(expected.Items) ||
+		actual.Notes == nil || *actual.Notes != notes {
+		t.Fatalf("Bind nested: (expected) %#v != %#v (actual)", expected, actual)
+	}
+	valEq(t, "order.Items[2].Options", reflect.ValueOf(actual.Items[2].Options), reflect.ValueOf(expected.Items[2].Options))
+	valEq(t, "order.Tags", reflect.ValueOf(actual.Tags), reflect.ValueOf(expected.Tags))
+}
+
+func TestBindMaxIndex(t *testing.T) {
+	params := &Params{Values: url.Values{
+		"ids[0]":                               {"1"},
+		fmt.Sprintf("ids[%d]", MaxBindIndex+1): {"2"},
+		"ids[-1]":                              {"3"},
+		"ids[x]":                               {"4"},
+	}}
+	valEq(t, "ids", Bind(params, "ids", reflect.TypeOf([]int{})), reflect.ValueOf([]int{1}))
+}
+
+func TestBindMaxElements(t *testing.T) {
+	defer func(savedIndex, savedElements int) {
+		MaxBindIndex, MaxBindElements = savedIndex, savedElements
+	}(MaxBindIndex, MaxBindElements)
+	MaxBindIndex, MaxBindElements = 100, 1000
+
+	// Every nested slice is within MaxBindIndex, but not all of them together.
+	params := &Params{Values: url.Values{}}
+	for i := 0; i <= MaxBindIndex; i++ {
+		params.Values.Set(fmt.Sprintf("a[%d][%d]", i, MaxBindIndex), "1")
+	}
+	actual := Bind(params, "a", reflect.TypeOf([][]int{})).Interface().([][]int)
+	total := len(actual)
+	for _, inner := range actual {
+		total += len(inner)
+	}
+	if total > MaxBindElements {
+		t.Errorf("Expected at most %d elements, got %d", MaxBindElements, total)
+	}
+
+	// The budget is per call.
+	params = &Params{Values: url.Values{"ids[0]": {"1"}, "ids[1]": {"2"}}}
+	for i := 0; i < 3; i++ {
+		valEq(t, "ids", Bind(params, "ids", reflect.TypeOf([]int{})), reflect.ValueOf([]int{1, 2}))
+	}
+}
+
+func TestUnbindNested(t *testing.T) {
+	notes := "fragile"
+	order := Order{
+		Customer: Customer{Email: "rob@example.com"},
+		Items:    []Item{{Sku: "a1", Options: map[string]string{"color": "red"}}},
+		Notes:    &notes,
+	}
+	expected := map[string]string{
+		"order.Email":                   "rob@example.com",
+		"order.Items[0].Sku":            "a1",
+		"order.Items[0].Options[color]": "red",
+		"order.Notes":                   "fragile",
+	}
+
+	actual := make(map[string]string)
+	Unbind(actual, "order", order)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unbind nested: (expected) %v != %v (actual)", expected, actual)
+	}
+}
+
 // Unbinding tests
 
 var unbinderTestCases = map[string]interface{}{
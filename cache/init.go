@@ -7,6 +7,16 @@ import (
 )
 
 func init() {
+	// Keep kept validation errors and flashed params in the cache when
+	// validation.store = cache.
+	revel.RegisterValidationStore("cache", revel.NewCacheValidationStore(instance{}, DEFAULT))
+
+	// Keep the sessions in the cache when session.store = cache.
+	revel.RegisterSessionStore("cache", revel.NewServerSessionStore(SessionBackend{instance{}}))
+
+	// Share the counters of the RateLimitFilter when ratelimit.store = cache.
+	revel.RegisterRateLimitStore("cache", instance{})
+
 	revel.OnAppStart(func() {
 		// Set the default expiration time.
 		defaultExpiration := time.Hour // The default for the default is one hour.
@@ -51,3 +61,18 @@ func init() {
 		Instance = NewInMemoryCache(defaultExpiration)
 	})
 }
+
+// instance forwards to the Instance set up on app start.
+type instance struct{}
+
+func (instance) Get(key string, ptrValue interface{}) error { return Get(key, ptrValue) }
+func (instance) Delete(key string) error                    { return Delete(key) }
+func (instance) Increment(key string, n uint64) (uint64, error) {
+	return Increment(key, n)
+}
+func (instance) Set(key string, value interface{}, expires time.Duration) error {
+	return Set(key, value, expires)
+}
+func (instance) Add(key string, value interface{}, expires time.Duration) error {
+	return Add(key, value, expires)
+}
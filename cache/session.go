@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"github.com/golib/revel"
+	"sync"
+	"time"
+)
+
+// SessionBackend keeps revel sessions in the cache, for
+// session.store = cache. As the cache can not be searched, the ids of the
+// sessions of each user are kept in the cache as well, until the last of them
+// expires.
+//
+// The updates of the index of a user are serialized in this process, but
+// servers sharing a cache may race on them, as the cache has no atomic update:
+// a session saved by another server at the same time may then be missing from
+// the index, and be kept by DeleteUser until it expires.
+type SessionBackend struct {
+	Cache interface {
+		Getter
+		Set(key string, value interface{}, expires time.Duration) error
+		Delete(key string) error
+	}
+}
+
+func (b SessionBackend) Get(id string) (revel.Session, error) {
+	var session revel.Session
+	if err := b.Cache.Get(sessionKey(id), &session); err == ErrCacheMiss {
+		return nil, revel.ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (b SessionBackend) Set(id string, session revel.Session, expires time.Time) error {
+	if err := b.Cache.Set(sessionKey(id), session, expires.Sub(time.Now())); err != nil {
+		return err
+	}
+
+	user, ok := session[revel.SESSION_USER_KEY]
+	if !ok {
+		return nil
+	}
+
+	userSessionsMutex.Lock()
+	defer userSessionsMutex.Unlock()
+
+	// Drop the expired sessions from the index, and keep it until the last one
+	// expires.
+	var (
+		now      = time.Now()
+		sessions []UserSession
+		indexed  []UserSession
+		last     time.Time
+	)
+	b.Cache.Get(userSessionsKey(user), &sessions)
+	for _, userSession := range sessions {
+		if userSession.Id == id || !userSession.Expires.After(now) {
+			continue
+		}
+		indexed = append(indexed, userSession)
+		if userSession.Expires.After(last) {
+			last = userSession.Expires
+		}
+	}
+	if expires.After(now) {
+		indexed = append(indexed, UserSession{id, expires})
+		if expires.After(last) {
+			last = expires
+		}
+	}
+	if len(indexed) == 0 {
+		if err := b.Cache.Delete(userSessionsKey(user)); err != nil && err != ErrCacheMiss {
+			return err
+		}
+		return nil
+	}
+	return b.Cache.Set(userSessionsKey(user), indexed, last.Sub(now))
+}
+
+// A UserSession is an entry of the index of the sessions of a user.
+type UserSession struct {
+	Id      string
+	Expires time.Time
+}
+
+// userSessionsMutex serializes the updates of the indexes of the sessions of
+// the users.
+var userSessionsMutex sync.Mutex
+
+func (b SessionBackend) Delete(id string) error {
+	if err := b.Cache.Delete(sessionKey(id)); err != nil && err != ErrCacheMiss {
+		return err
+	}
+	return nil
+}
+
+func (b SessionBackend) DeleteUser(user string) error {
+	userSessionsMutex.Lock()
+	defer userSessionsMutex.Unlock()
+
+	var sessions []UserSession
+	if err := b.Cache.Get(userSessionsKey(user), &sessions); err == ErrCacheMiss {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, userSession := range sessions {
+		if err := b.Delete(userSession.Id); err != nil {
+			return err
+		}
+	}
+	if err := b.Cache.Delete(userSessionsKey(user)); err != nil && err != ErrCacheMiss {
+		return err
+	}
+	return nil
+}
+
+// Sweep does nothing, as the cache expires the sessions by itself.
+func (b SessionBackend) Sweep() error {
+	return nil
+}
+
+func sessionKey(id string) string {
+	return "revel.session:" + id
+}
+
+func userSessionsKey(user string) string {
+	return "revel.session.user:" + user
+}
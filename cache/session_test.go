@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"github.com/golib/revel"
+	"testing"
+	"time"
+)
+
+func TestSessionBackend(t *testing.T) {
+	backend := SessionBackend{NewInMemoryCache(time.Hour)}
+	expires := time.Now().Add(time.Hour)
+
+	for _, id := range []string{"first", "second"} {
+		if err := backend.Set(id, revel.Session{revel.SESSION_USER_KEY: "tom"}, expires); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backend.Set("other", revel.Session{revel.SESSION_USER_KEY: "jerry"}, expires)
+
+	if session, err := backend.Get("first"); err != nil || session[revel.SESSION_USER_KEY] != "tom" {
+		t.Errorf("Expected the session of tom, got %v, %v", session, err)
+	}
+
+	if err := backend.DeleteUser("tom"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"first", "second"} {
+		if _, err := backend.Get(id); err != revel.ErrSessionNotFound {
+			t.Errorf("Expected session %s to be deleted, got %v", id, err)
+		}
+	}
+	if _, err := backend.Get("other"); err != nil {
+		t.Errorf("Expected the session of jerry to be kept, got %v", err)
+	}
+}
+
+func TestSessionBackendIndex(t *testing.T) {
+	cache := NewInMemoryCache(time.Hour)
+	backend := SessionBackend{cache}
+	tom := revel.Session{revel.SESSION_USER_KEY: "tom"}
+
+	backend.Set("expired", tom, time.Now().Add(-time.Minute))
+	backend.Set("first", tom, time.Now().Add(time.Hour))
+	backend.Set("first", tom, time.Now().Add(2*time.Hour))
+	backend.Set("second", tom, time.Now().Add(time.Minute))
+
+	var sessions []UserSession
+	if err := cache.Get(userSessionsKey("tom"), &sessions); err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].Id != "first" || sessions[1].Id != "second" {
+		t.Errorf("Expected the live sessions of tom in the index, got %v", sessions)
+	}
+
+	// The index expires with the last session.
+	backend = SessionBackend{NewInMemoryCache(time.Hour)}
+	backend.Set("short", tom, time.Now().Add(50*time.Millisecond))
+	time.Sleep(100 * time.Millisecond)
+	if err := backend.Cache.Get(userSessionsKey("tom"), &sessions); err != ErrCacheMiss {
+		t.Errorf("Expected the index to expire with its sessions, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package revel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// The header of the request id, which is taken from trusted proxies, and set
+// on the response.
+const REQUEST_ID_HEADER = "X-Request-Id"
+
+type contextKey int
+
+const requestIdContextKey contextKey = iota
+
+// Context returns the context of the request. It is cancelled when the
+// client disconnects, or the request is done, and carries the request id.
+// It should be passed on to database queries and outgoing requests, e.g.
+//   rows, err := db.QueryContext(c.Context(), "SELECT ...")
+func (c *Controller) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	if c.Request != nil && c.Request.Request != nil {
+		return c.Request.Request.Context()
+	}
+	return context.Background()
+}
+
+// SetContext replaces the context of the request. Filters and interceptors
+// may wrap Context with values, or a deadline:
+//   ctx, cancel := context.WithTimeout(c.Context(), time.Second)
+//   defer cancel()
+//   c.SetContext(ctx)
+func (c *Controller) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// RequestIdFromContext returns the id of the request of the context, or ""
+// if there is none.
+func RequestIdFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdContextKey).(string)
+	return id
+}
+
+// newRequestContext returns the context of a new request, which carries its
+// id, and is cancelled when the client disconnects. The request id is also
+// set on the response.
+func newRequestContext(req *Request, resp *Response) (context.Context, context.CancelFunc) {
+	id := req.Header.Get(REQUEST_ID_HEADER)
+	if id == "" || !isTrustedProxy(req.remoteIP()) {
+		id = newRequestId()
+	}
+	resp.Out.Header().Set(REQUEST_ID_HEADER, id)
+
+	ctx, cancel := context.WithCancel(context.WithValue(req.Request.Context(), requestIdContextKey, id))
+	if notifier, ok := resp.Out.(http.CloseNotifier); ok && req.Websocket == nil {
+		closed := notifier.CloseNotify()
+		go func() {
+			select {
+			case <-closed:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return ctx, cancel
+}
+
+func newRequestId() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,64 @@
+package revel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// closeNotifyRecorder is a ResponseRecorder whose client may disconnect.
+type closeNotifyRecorder struct {
+	*httptest.ResponseRecorder
+	closed chan bool
+}
+
+func (r closeNotifyRecorder) CloseNotify() <-chan bool {
+	return r.closed
+}
+
+func TestRequestContext(t *testing.T) {
+	withTrustedProxies(t, []string{"10.0.0.0/8"}, func() {
+		recorder := closeNotifyRecorder{httptest.NewRecorder(), make(chan bool, 1)}
+		req := proxiedRequest("192.0.2.1:1234", map[string]string{REQUEST_ID_HEADER: "spoofed"})
+		ctx, cancel := newRequestContext(req, NewResponse(recorder))
+		defer cancel()
+
+		id := RequestIdFromContext(ctx)
+		if id == "" || id == "spoofed" || recorder.Header().Get(REQUEST_ID_HEADER) != id {
+			t.Errorf("Expected a new request id on the context and response, got %q", id)
+		}
+
+		recorder.closed <- true
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("Expected the context to be cancelled when the client disconnects")
+		}
+
+		req = proxiedRequest("10.0.0.1:1234", map[string]string{REQUEST_ID_HEADER: "upstream"})
+		ctx, cancel = newRequestContext(req, NewResponse(httptest.NewRecorder()))
+		cancel()
+		if id := RequestIdFromContext(ctx); id != "upstream" {
+			t.Errorf("Expected the request id of the trusted proxy, got %q", id)
+		}
+		if ctx.Err() != context.Canceled {
+			t.Errorf("Expected the context to be cancelled, got %v", ctx.Err())
+		}
+	})
+}
+
+func TestControllerContext(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	c := NewController(NewRequest(httpRequest), NewResponse(httptest.NewRecorder()))
+	if c.Context() != httpRequest.Context() {
+		t.Errorf("Expected the context of the http.Request by default")
+	}
+
+	ctx := context.WithValue(c.Context(), requestIdContextKey, "wrapped")
+	c.SetContext(ctx)
+	if RequestIdFromContext(c.Context()) != "wrapped" {
+		t.Errorf("Expected the context set by SetContext")
+	}
+}
@@ -1,6 +1,7 @@
 package revel
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"strings"
@@ -67,6 +68,12 @@ type Controller struct {
 	Args       map[string]interface{} // Per-request scratch space.
 	RenderArgs map[string]interface{} // Args passed to the template.
 	Validation *Validation            // Data validation helpers
+
+	// The error recovered from a panic of the action, for the PANIC and
+	// FINALLY interceptors.
+	PanicError error
+
+	ctx context.Context // The context of the request, see Context.
 }
 
 func NewController(req *Request, res *Response) *Controller {
@@ -78,6 +85,9 @@ func NewController(req *Request, res *Response) *Controller {
 		RenderArgs: map[string]interface{}{
 			"RunMode": RunMode,
 			"DevMode": DevMode,
+
+			// Set by the SecureHeadersFilter.
+			CSPNonceRenderArg: "",
 		},
 	}
 }
@@ -51,10 +51,20 @@ func (c *Controller) SetCookie(cookie *http.Cookie) {
 }
 
 // FlashParams serializes the contents of Controller.Params to the Flash
-// cookie.
+// cookie, or to the configured ValidationStore.
 func (c *Controller) FlashParams() {
+	if c.Validation == nil {
+		for key, vals := range c.Params.Values {
+			c.Flash.Out[key] = strings.Join(vals, ",")
+		}
+		return
+	}
+
+	if c.Validation.params == nil {
+		c.Validation.params = make(map[string]string)
+	}
 	for key, vals := range c.Params.Values {
-		c.Flash.Out[key] = strings.Join(vals, ",")
+		c.Validation.params[key] = strings.Join(vals, ",")
 	}
 }
 
@@ -205,7 +215,14 @@ func (c *Controller) CaptureTemplate(name string) template.HTML {
 }
 
 // Render an error in request.Format
+// Unless the status of the response is set, the errors registered with
+// RegisterErrorStatus are rendered with their status.
 func (c *Controller) RenderError(err error) Result {
+	if c.Response != nil && c.Response.Status == 0 {
+		if result, ok := registeredErrorResult(c, err); ok {
+			return result
+		}
+	}
 	return ErrorResult{c.RenderArgs, err}
 }
 
@@ -296,6 +313,22 @@ func (c *Controller) Redirect(val interface{}, args ...interface{}) Result {
 	return &RedirectToActionResult{val, actionArgs}
 }
 
+// Unauthorized returns an HTTP 401 Unauthorized response whose body is the
+// formatted string of msg and args.
+func (c *Controller) Unauthorized(msg string, args ...interface{}) Result {
+	s := msg
+	if len(args) > 0 {
+		s = fmt.Sprintf(msg, args...)
+	}
+
+	c.Response.Status = http.StatusUnauthorized
+
+	return c.RenderError(&Error{
+		Title:       "Unauthorized",
+		Description: s,
+	})
+}
+
 // Forbidden returns an HTTP 403 Forbidden response whose body is the
 // formatted string of msg and args.
 func (c *Controller) Forbidden(msg string, args ...interface{}) Result {
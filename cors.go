@@ -0,0 +1,207 @@
+package revel
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A CORSPolicy describes the cross-origin requests allowed by the CORSFilter.
+type CORSPolicy struct {
+	AllowedOrigins   []string // e.g. "https://app.example.com", or "*" for any origin
+	AllowedMethods   []string
+	AllowedHeaders   []string // "*" allows any header
+	ExposedHeaders   []string // The response headers readable by the client.
+	AllowCredentials bool     // Whether cookies and credentials may be sent.
+	MaxAge           time.Duration
+}
+
+var (
+	// The policy configured in app.conf with cors.*.
+	defaultCORSPolicy CORSPolicy
+
+	// Map from "Controller" or "Controller.Method" to its policy.
+	corsPolicies = make(map[string]CORSPolicy)
+)
+
+func init() {
+	OnAppStart(func() {
+		maxAge, err := time.ParseDuration(Config.StringDefault("cors.max_age", "0"))
+		if err != nil {
+			ERROR.Fatalln("cors.max_age invalid:", err)
+		}
+		defaultCORSPolicy = CORSPolicy{
+			AllowedOrigins:   splitConfigList(Config.StringDefault("cors.allowed_origins", "")),
+			AllowedMethods:   splitConfigList(Config.StringDefault("cors.allowed_methods", "GET,HEAD,POST,PUT,PATCH,DELETE")),
+			AllowedHeaders:   splitConfigList(Config.StringDefault("cors.allowed_headers", "Accept,Authorization,Content-Type,X-CSRF-Token,X-Requested-With")),
+			ExposedHeaders:   splitConfigList(Config.StringDefault("cors.exposed_headers", "")),
+			AllowCredentials: Config.BoolDefault("cors.allow_credentials", false),
+			MaxAge:           maxAge,
+		}
+		if err := defaultCORSPolicy.validate(); err != nil {
+			ERROR.Fatalln("cors invalid:", err)
+		}
+	})
+}
+
+// CORS sets the policy of the CORSFilter for the controller or action, in
+// place of the one configured in app.conf. For example:
+//   revel.FilterController(Api{}).
+//     CORS(revel.CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+func (conf FilterConfigurator) CORS(policy CORSPolicy) FilterConfigurator {
+	if err := policy.validate(); err != nil {
+		panic("revel: " + err.Error())
+	}
+	corsPolicies[conf.key] = policy
+	return conf
+}
+
+// CORSFilter is a Revel Filter that allows cross-origin requests, as allowed
+// by "cors.allowed_origins" and the related settings in app.conf, or by the
+// policy of the action set with FilterConfigurator.CORS.
+//
+// It answers preflight OPTIONS requests itself, for the action routed with the
+// requested method, so it must come before the RouterFilter.
+func CORSFilter(c *Controller, fc []Filter) {
+	origin := c.Request.Header.Get("Origin")
+	if origin == "" {
+		fc[0](c, fc[1:])
+		return
+	}
+
+	header := c.Response.Out.Header()
+	header.Add("Vary", "Origin")
+	method := c.Request.Header.Get("Access-Control-Request-Method")
+	if c.Request.Method == "OPTIONS" && method != "" {
+		corsPreflight(c, origin, strings.ToUpper(method))
+		return
+	}
+
+	fc[0](c, fc[1:])
+
+	// The headers are added once the action is known, but before the result
+	// is applied.
+	policy := corsPolicy(c)
+	if !policy.allowsOrigin(origin) {
+		return
+	}
+	policy.setOrigin(header, origin)
+	if len(policy.ExposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+	}
+}
+
+// corsPreflight answers a preflight request for the given method.
+func corsPreflight(c *Controller, origin, method string) {
+	if MainRouter != nil {
+		request := *c.Request.Request
+		request.Method = method
+		if route := MainRouter.Route(&request); route != nil && route.Action != "404" {
+			c.SetAction(route.ControllerName, route.MethodName)
+		}
+	}
+
+	policy := corsPolicy(c)
+	requestedHeaders := splitConfigList(c.Request.Header.Get("Access-Control-Request-Headers"))
+	if !policy.allowsOrigin(origin) || !containsFold(policy.AllowedMethods, method) ||
+		!policy.allowsHeaders(requestedHeaders) {
+		c.Result = c.Forbidden("Cross-origin request rejected")
+		return
+	}
+
+	header := c.Response.Out.Header()
+	policy.setOrigin(header, origin)
+	header.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+	if len(requestedHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+	}
+	if policy.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+	}
+	c.Result = noContentResult{}
+}
+
+// corsPolicy returns the policy of the action, or else of the controller, or
+// else the configured one.
+func corsPolicy(c *Controller) CORSPolicy {
+	if policy, ok := corsPolicies[c.Action]; ok {
+		return policy
+	}
+	if policy, ok := corsPolicies[c.Name]; ok {
+		return policy
+	}
+	return defaultCORSPolicy
+}
+
+// validate rejects a policy that would let any website make requests with
+// the credentials of the user.
+func (policy CORSPolicy) validate() error {
+	if policy.AllowCredentials && containsFold(policy.AllowedOrigins, "*") {
+		return errors.New("allowed origins may not include * when credentials are allowed")
+	}
+	return nil
+}
+
+// allowsOrigin returns whether the origin is allowed. Only the origins listed
+// explicitly are allowed with credentials.
+func (policy CORSPolicy) allowsOrigin(origin string) bool {
+	for _, allowed := range policy.AllowedOrigins {
+		if (allowed == "*" && !policy.AllowCredentials) || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (policy CORSPolicy) allowsHeaders(headers []string) bool {
+	if containsFold(policy.AllowedHeaders, "*") {
+		return true
+	}
+	for _, header := range headers {
+		if !containsFold(policy.AllowedHeaders, header) {
+			return false
+		}
+	}
+	return true
+}
+
+// setOrigin allows the origin, which is only given as "*" to requests without
+// credentials.
+func (policy CORSPolicy) setOrigin(header http.Header, origin string) {
+	if policy.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	} else if containsFold(policy.AllowedOrigins, "*") {
+		origin = "*"
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+}
+
+// noContentResult is the empty 204 response to a preflight request.
+type noContentResult struct{}
+
+func (r noContentResult) Apply(req *Request, resp *Response) {
+	resp.Status = http.StatusNoContent
+	resp.Out.WriteHeader(http.StatusNoContent)
+}
+
+// splitConfigList splits a comma separated list, dropping empty items.
+func splitConfigList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
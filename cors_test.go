@@ -0,0 +1,120 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// corsTester runs the CORSFilter for a request with the given method and
+// headers, and reports whether the rest of the chain was reached.
+func corsTester(method, path string, headers map[string]string) (*Controller, bool) {
+	httpRequest, _ := http.NewRequest(method, "http://api.example.com"+path, nil)
+	for key, value := range headers {
+		httpRequest.Header.Set(key, value)
+	}
+	c := NewController(NewRequest(httpRequest), NewResponse(httptest.NewRecorder()))
+
+	invoked := false
+	CORSFilter(c, []Filter{func(c *Controller, _ []Filter) {
+		// Route the request, as the RouterFilter would.
+		c.Name, c.Action = "Hotels", "Hotels.Index"
+		invoked = true
+	}})
+	return c, invoked
+}
+
+func TestCORSFilter(t *testing.T) {
+	fakeTestApp()
+	defer func(policy CORSPolicy) { defaultCORSPolicy = policy }(defaultCORSPolicy)
+	defaultCORSPolicy = CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}
+
+	// A preflight is answered without reaching the router.
+	c, invoked := corsTester("OPTIONS", "/hotels", map[string]string{
+		"Origin":                         "https://app.example.com",
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "content-type",
+	})
+	header := c.Response.Out.Header()
+	if invoked || c.Result == nil || header.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		header.Get("Access-Control-Allow-Credentials") != "true" || header.Get("Access-Control-Max-Age") != "3600" ||
+		header.Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Errorf("Unexpected preflight response: %v", header)
+	}
+
+	for _, headers := range []map[string]string{
+		{"Origin": "https://evil.com", "Access-Control-Request-Method": "GET"},
+		{"Origin": "https://app.example.com", "Access-Control-Request-Method": "DELETE"},
+		{"Origin": "https://app.example.com", "Access-Control-Request-Method": "GET",
+			"Access-Control-Request-Headers": "X-Secret"},
+	} {
+		c, _ = corsTester("OPTIONS", "/hotels", headers)
+		if c.Response.Status != http.StatusForbidden || c.Response.Out.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("Expected the preflight %v to be rejected", headers)
+		}
+	}
+
+	// Actual requests reach the action and get the CORS headers.
+	c, invoked = corsTester("GET", "/hotels", map[string]string{"Origin": "https://app.example.com"})
+	if !invoked || c.Response.Out.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("Unexpected response: %v", c.Response.Out.Header())
+	}
+	c, invoked = corsTester("GET", "/hotels", map[string]string{"Origin": "https://evil.com"})
+	if !invoked || c.Response.Out.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected no CORS headers for another origin: %v", c.Response.Out.Header())
+	}
+}
+
+func TestCORSControllerPolicy(t *testing.T) {
+	fakeTestApp()
+	defer delete(corsPolicies, "Hotels")
+	FilterController(Hotels{}).CORS(CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET"},
+		AllowedHeaders: []string{"*"},
+	})
+
+	c, _ := corsTester("OPTIONS", "/hotels", map[string]string{
+		"Origin":                         "https://other.example.com",
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "X-Anything",
+	})
+	if c.Action != "Hotels.Index" || c.Response.Out.Header().Get("Access-Control-Allow-Origin") != "*" ||
+		c.Response.Out.Header().Get("Access-Control-Allow-Headers") != "X-Anything" {
+		t.Errorf("Expected the Hotels policy to apply to %s: %v", c.Action, c.Response.Out.Header())
+	}
+
+	c, _ = corsTester("GET", "/hotels", map[string]string{"Origin": "https://other.example.com"})
+	if c.Response.Out.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Unexpected response: %v", c.Response.Out.Header())
+	}
+}
+
+func TestCORSCredentialsWithAnyOrigin(t *testing.T) {
+	policy := CORSPolicy{
+		AllowedOrigins:   []string{"*", "https://app.example.com"},
+		AllowedMethods:   []string{"GET"},
+		AllowCredentials: true,
+	}
+	if policy.validate() == nil {
+		t.Errorf("Expected * to be rejected with credentials")
+	}
+	if policy.allowsOrigin("https://evil.com") || !policy.allowsOrigin("https://app.example.com") {
+		t.Errorf("Expected only the listed origin to be allowed with credentials")
+	}
+
+	defer func() {
+		if err := recover(); err == nil {
+			t.Errorf("Expected FilterConfigurator.CORS to reject the policy")
+		}
+		delete(corsPolicies, "Hotels")
+	}()
+	FilterConfigurator{key: "Hotels", controllerName: "Hotels"}.CORS(policy)
+}
@@ -0,0 +1,111 @@
+package revel
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"html"
+	"html/template"
+	"net/url"
+)
+
+const (
+	CSRF_SESSION_KEY = "_CSRF"        // The session key of the CSRF token.
+	CSRF_HEADER      = "X-CSRF-Token" // The header carrying the token of ajax requests.
+	CSRF_TOKEN_LEN   = 32
+
+	// The render arg holding the CSRF token, e.g. for use in ajax requests.
+	CSRFTokenRenderArg = "csrfToken"
+)
+
+// The name of the form field carrying the CSRF token. It may be set in config
+// as "csrf.field".
+var csrfField = "csrf_token"
+
+func init() {
+	OnAppStart(func() {
+		csrfField = Config.StringDefault("csrf.field", "csrf_token")
+	})
+}
+
+// CSRFFilter is a Revel Filter that protects against cross-site request
+// forgery. It keeps a random token in the session, and requires requests
+// with an unsafe method (e.g. POST) to send it back, either in the form field
+// "csrf.field" (see the csrfField template helper) or in the X-CSRF-Token
+// header. Over HTTPS, the Origin or Referer header must also match the host,
+// as seen by the client (see Request.Scheme and Request.RequestHost).
+// Websocket (WS) requests are not checked.
+//
+// It must run after the ParamsFilter and the SessionFilter. An action (e.g. a
+// webhook) may be exempted with:
+//   revel.FilterAction(App.Webhook).Remove(revel.CSRFFilter)
+func CSRFFilter(c *Controller, fc []Filter) {
+	token, ok := c.Session[CSRF_SESSION_KEY]
+	if !ok || len(token) != 2*CSRF_TOKEN_LEN {
+		token = newCSRFToken()
+		c.Session[CSRF_SESSION_KEY] = token
+	}
+	c.RenderArgs[CSRFTokenRenderArg] = token
+
+	switch c.Request.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "WS":
+		fc[0](c, fc[1:])
+		return
+	}
+
+	if c.Request.Scheme() == "https" {
+		if !isSameOrigin(c) {
+			c.Result = c.Forbidden("Cross-site request rejected: bad origin")
+			return
+		}
+	}
+
+	sent := c.Request.Header.Get(CSRF_HEADER)
+	if sent == "" && c.Params != nil && c.Params.Form != nil {
+		sent = c.Params.Form.Get(csrfField)
+	}
+	if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+		c.Result = c.Forbidden("Cross-site request rejected: invalid CSRF token")
+		return
+	}
+
+	fc[0](c, fc[1:])
+}
+
+// isSameOrigin reports whether the Origin header, or the Referer header in its
+// absence, has the host of the request.
+func isSameOrigin(c *Controller) bool {
+	source := c.Request.Header.Get("Origin")
+	if source == "" {
+		source = c.Request.Header.Get("Referer")
+	}
+	if source == "" {
+		return false
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "https" && u.Host == c.Request.RequestHost()
+}
+
+func newCSRFToken() string {
+	buf := make([]byte, CSRF_TOKEN_LEN)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// csrfFieldHTML returns the hidden form field carrying the CSRF token in the
+// given render args.
+func csrfFieldHTML(renderArgs map[string]interface{}) template.HTML {
+	token, ok := renderArgs[CSRFTokenRenderArg].(string)
+	if !ok {
+		WARN.Println("csrfField: no CSRF token, is the CSRFFilter enabled?")
+		return ""
+	}
+	return template.HTML(`<input type="hidden" name="` + html.EscapeString(csrfField) +
+		`" value="` + token + `">`)
+}
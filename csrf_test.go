@@ -0,0 +1,85 @@
+package revel
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// csrfTester runs the CSRFFilter for a request with the given method, session
+// and form, and reports whether the action was reached.
+func csrfTester(method string, session Session, form url.Values, prepare func(req *http.Request)) (*Controller, bool) {
+	httpRequest, _ := http.NewRequest(method, "http://example.com/", nil)
+	if prepare != nil {
+		prepare(httpRequest)
+	}
+	c := NewController(NewRequest(httpRequest), NewResponse(httptest.NewRecorder()))
+	c.Session = session
+	c.Params = &Params{Form: form}
+
+	invoked := false
+	CSRFFilter(c, []Filter{func(c *Controller, _ []Filter) {
+		invoked = true
+	}})
+	return c, invoked
+}
+
+func TestCSRFFilter(t *testing.T) {
+	session := make(Session)
+	c, invoked := csrfTester("GET", session, nil, nil)
+	token := session[CSRF_SESSION_KEY]
+	if !invoked || token == "" || c.RenderArgs[CSRFTokenRenderArg] != token {
+		t.Fatalf("Expected a GET request to pass and get a token, got %v", session)
+	}
+	if _, invoked = csrfTester("WS", session, nil, nil); !invoked {
+		t.Errorf("Expected a websocket request to pass")
+	}
+
+	if c, invoked = csrfTester("POST", session, nil, nil); invoked || c.Response.Status != http.StatusForbidden {
+		t.Errorf("Expected a POST without a token to be forbidden")
+	}
+	if _, invoked = csrfTester("POST", session, url.Values{"csrf_token": {"bad"}}, nil); invoked {
+		t.Errorf("Expected a POST with a bad token to be forbidden")
+	}
+	if _, invoked = csrfTester("POST", session, url.Values{"csrf_token": {token}}, nil); !invoked {
+		t.Errorf("Expected a POST with the form token to pass")
+	}
+	if _, invoked = csrfTester("DELETE", session, nil, func(req *http.Request) {
+		req.Header.Set(CSRF_HEADER, token)
+	}); !invoked {
+		t.Errorf("Expected a DELETE with the token header to pass")
+	}
+
+	// Over HTTPS, the request must also come from the same origin.
+	for origin, expected := range map[string]bool{
+		"https://example.com": true,
+		"https://evil.com":    false,
+		"http://example.com":  false,
+		"":                    false,
+	} {
+		_, invoked = csrfTester("POST", session, url.Values{"csrf_token": {token}}, func(req *http.Request) {
+			req.TLS = &tls.ConnectionState{}
+			if origin != "" {
+				req.Header.Set("Origin", origin)
+			}
+		})
+		if invoked != expected {
+			t.Errorf("Expected origin %q to pass: %v", origin, expected)
+		}
+	}
+	if _, invoked = csrfTester("POST", session, url.Values{"csrf_token": {token}}, func(req *http.Request) {
+		req.TLS = &tls.ConnectionState{}
+		req.Header.Set("Referer", "https://example.com/form")
+	}); !invoked {
+		t.Errorf("Expected a same site referer to pass")
+	}
+}
+
+func TestCSRFField(t *testing.T) {
+	field := csrfFieldHTML(map[string]interface{}{CSRFTokenRenderArg: "abc"})
+	if field != `<input type="hidden" name="csrf_token" value="abc">` {
+		t.Errorf("Unexpected CSRF field: %s", field)
+	}
+}
@@ -1,7 +1,11 @@
 package revel
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
 	"runtime/debug"
 	"strings"
 )
@@ -115,3 +119,91 @@ func findRelevantStackFrame(stack string) (int, string) {
 	}
 	return -1, ""
 }
+
+// Errors that actions may panic with, or render with Controller.RenderError,
+// to respond with the matching status. They may be wrapped, e.g.
+//   panic(fmt.Errorf("hotel %d: %w", id, revel.ErrNotFound))
+var (
+	ErrUnauthorized = errors.New("revel: unauthorized")
+	ErrForbidden    = errors.New("revel: forbidden")
+	ErrNotFound     = errors.New("revel: not found")
+)
+
+// An ErrorResultFunc returns the result of an error registered with
+// RegisterErrorResult.
+type ErrorResultFunc func(c *Controller, err error) Result
+
+type errorMapping struct {
+	err     error
+	errType reflect.Type // Set to match all the errors of a type.
+	status  int
+	result  ErrorResultFunc
+}
+
+var errorMappings []*errorMapping
+
+func init() {
+	RegisterErrorStatus(ErrUnauthorized, http.StatusUnauthorized)
+	RegisterErrorStatus(ErrForbidden, http.StatusForbidden)
+	RegisterErrorStatus(ErrNotFound, http.StatusNotFound)
+	RegisterErrorStatus(sql.ErrNoRows, http.StatusNotFound)
+}
+
+// RegisterErrorStatus responds to err, and the errors wrapping it, with the
+// given HTTP status and its error page (errors/<status>.<format>). A nil
+// pointer of an error type, e.g. (*MyError)(nil), matches all the errors of
+// that type. The errors registered last take precedence.
+func RegisterErrorStatus(err error, status int) {
+	RegisterErrorResult(err, status, nil)
+}
+
+// RegisterErrorResult is like RegisterErrorStatus, but the response is the
+// Result returned by the given function, which is run after the status is
+// set.
+func RegisterErrorResult(err error, status int, result ErrorResultFunc) {
+	mapping := &errorMapping{err: err, status: status, result: result}
+	if value := reflect.ValueOf(err); value.Kind() == reflect.Ptr && value.IsNil() {
+		mapping.errType = value.Type()
+	}
+	errorMappings = append([]*errorMapping{mapping}, errorMappings...)
+}
+
+func (m *errorMapping) matches(err error) bool {
+	if m.errType != nil {
+		return errors.As(err, reflect.New(m.errType).Interface())
+	}
+	return errors.Is(err, m.err)
+}
+
+// registeredErrorResult returns the result of the registered error matching
+// err, after setting the status of the response, or false if there is none.
+func registeredErrorResult(c *Controller, err error) (Result, bool) {
+	for _, mapping := range errorMappings {
+		if !mapping.matches(err) {
+			continue
+		}
+		c.Response.Status = mapping.status
+		if mapping.result != nil {
+			return mapping.result(c, err), true
+		}
+		// The error may wrap internal details, only shown in dev mode.
+		description := http.StatusText(mapping.status)
+		if DevMode {
+			description = err.Error()
+		}
+		INFO.Printf("%s responded %d: %v", c.Action, mapping.status, err)
+		return ErrorResult{c.RenderArgs, &Error{
+			Title:       http.StatusText(mapping.status),
+			Description: description,
+		}}, true
+	}
+	return nil, false
+}
+
+// panicError returns the value recovered from a panic as an error.
+func panicError(recovered interface{}) error {
+	if err, ok := recovered.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", recovered)
+}
@@ -0,0 +1,106 @@
+package revel
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testStatusError struct{ code int }
+
+func (e *testStatusError) Error() string { return fmt.Sprint("status ", e.code) }
+
+func TestRegisteredErrors(t *testing.T) {
+	defer func(saved []*errorMapping) { errorMappings = saved }(errorMappings)
+	RegisterErrorStatus((*testStatusError)(nil), http.StatusConflict)
+	RegisterErrorResult(ErrForbidden, http.StatusForbidden, func(c *Controller, err error) Result {
+		return c.RenderText("denied")
+	})
+
+	for _, test := range []struct {
+		err      error
+		status   int
+		result   Result
+		rendered bool
+	}{
+		{fmt.Errorf("hotel 3: %w", ErrNotFound), http.StatusNotFound, nil, true},
+		{sql.ErrNoRows, http.StatusNotFound, nil, true},
+		{fmt.Errorf("saving: %w", &testStatusError{1}), http.StatusConflict, nil, true},
+		{ErrForbidden, http.StatusForbidden, &RenderTextResult{"denied"}, true},
+		{fmt.Errorf("unknown"), 0, nil, false},
+	} {
+		c := NewController(nil, NewResponse(httptest.NewRecorder()))
+		result := c.RenderError(test.err)
+		if c.Response.Status != test.status {
+			t.Errorf("Expected status %d for %v, got %d", test.status, test.err, c.Response.Status)
+		}
+		if test.result != nil && !reflect.DeepEqual(result, test.result) {
+			t.Errorf("Expected %v for %v, got %v", test.result, test.err, result)
+		}
+		if errorResult, ok := result.(ErrorResult); ok && test.rendered {
+			if title := errorResult.Error.(*Error).Title; title != http.StatusText(test.status) {
+				t.Errorf("Expected the title of the status for %v, got %s", test.err, title)
+			}
+		}
+	}
+
+	// The error is only described in dev mode.
+	defer func(saved bool) { DevMode = saved }(DevMode)
+	for _, devMode := range []bool{false, true} {
+		DevMode = devMode
+		err := fmt.Errorf("query SELECT * FROM hotels: %w", sql.ErrNoRows)
+		c := NewController(nil, NewResponse(httptest.NewRecorder()))
+		description := c.RenderError(err).(ErrorResult).Error.(*Error).Description
+		expected := http.StatusText(http.StatusNotFound)
+		if devMode {
+			expected = err.Error()
+		}
+		if description != expected {
+			t.Errorf("Expected %q with DevMode %v, got %q", expected, devMode, description)
+		}
+	}
+
+	// An explicit status is kept.
+	c := NewController(nil, NewResponse(httptest.NewRecorder()))
+	c.Response.Status = http.StatusServiceUnavailable
+	c.RenderError(ErrNotFound)
+	if c.Response.Status != http.StatusServiceUnavailable {
+		t.Errorf("Expected the status to be kept, got %d", c.Response.Status)
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	defer func(saved []*Interception) { interceptors = saved }(interceptors)
+	interceptors = []*Interception{}
+
+	var panicErr, finallyErr error
+	InterceptFunc(func(c *Controller) Result {
+		panicErr = c.PanicError
+		return nil
+	}, PANIC, ALL_CONTROLLERS)
+	InterceptFunc(func(c *Controller) Result {
+		finallyErr = c.PanicError
+		return nil
+	}, FINALLY, ALL_CONTROLLERS)
+
+	notFound := fmt.Errorf("hotel 3: %w", ErrNotFound)
+	httpRequest, _ := http.NewRequest("GET", "/hotels/3", nil)
+	c := NewController(NewRequest(httpRequest), NewResponse(httptest.NewRecorder()))
+	c.AppController = &InterceptController{c}
+	PanicFilter(c, []Filter{InterceptorFilter, func(c *Controller, _ []Filter) {
+		panic(notFound)
+	}})
+
+	if panicErr != notFound || finallyErr != notFound {
+		t.Errorf("Expected the interceptors to get the panic error, got %v and %v", panicErr, finallyErr)
+	}
+	if c.Response.Status != http.StatusNotFound {
+		t.Errorf("Expected a 404 for a panic with ErrNotFound, got %d", c.Response.Status)
+	}
+	if _, ok := c.Result.(ErrorResult); !ok {
+		t.Errorf("Expected an ErrorResult, got %#v", c.Result)
+	}
+}
@@ -2,6 +2,7 @@ package revel
 
 import (
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -22,10 +23,13 @@ func NewField(name string, renderArgs map[string]interface{}) *Field {
 }
 
 // Id returns an identifier suitable for use as an HTML id.
+// e.g. order.Items[3].Name => order_Items_3_Name
 func (f *Field) Id() string {
-	return strings.Replace(f.Name, ".", "_", -1)
+	return fieldIdReplacer.Replace(f.Name)
 }
 
+var fieldIdReplacer = strings.NewReplacer(".", "_", "[", "_", "]", "")
+
 // Flash returns the flashed value of this Field.
 func (f *Field) Flash() string {
 	v, _ := f.renderArgs["flash"].(map[string]string)[f.Name]
@@ -42,20 +46,43 @@ func (f *Field) FlashArray() []string {
 }
 
 // Value returns the current value of this Field.
+// The name is resolved with the same path syntax used by the binder,
+// e.g. order.Items[3].Options[color]
 func (f *Field) Value() interface{} {
-	pieces := strings.Split(f.Name, ".")
-	answer, ok := f.renderArgs[pieces[0]]
+	path := splitBindPath(f.Name)
+	if len(path) == 0 {
+		return ""
+	}
+	answer, ok := f.renderArgs[path[0]]
 	if !ok {
 		return ""
 	}
 
 	val := reflect.ValueOf(answer)
-	for i := 1; i < len(pieces); i++ {
-		if val.Kind() == reflect.Ptr {
+	for _, key := range path[1:] {
+		for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+			if val.IsNil() {
+				return ""
+			}
 			val = val.Elem()
 		}
-		val = val.FieldByName(pieces[i])
-		if !val.IsValid() {
+
+		switch val.Kind() {
+		case reflect.Struct:
+			val = lookupFieldByName(val, key)
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= val.Len() {
+				return ""
+			}
+			val = val.Index(index)
+		case reflect.Map:
+			val = val.MapIndex(BindValue(key, val.Type().Key()))
+		default:
+			return ""
+		}
+
+		if !val.IsValid() || !val.CanInterface() {
 			return ""
 		}
 	}
@@ -0,0 +1,45 @@
+package revel
+
+import "testing"
+
+func TestFieldPath(t *testing.T) {
+	renderArgs := map[string]interface{}{
+		"errors": map[string]*ValidationError{},
+		"order": &Order{
+			Customer: Customer{Email: "rob@example.com"},
+			Items:    []Item{{Sku: "a1", Options: map[string]string{"color": "red"}}},
+		},
+	}
+
+	for name, expected := range map[string]interface{}{
+		"order.Email":                   "rob@example.com",
+		"order.Items[0].Sku":            "a1",
+		"order.items[0].options[color]": "red",
+		"order.Items[1].Sku":            "",
+		"order.Missing":                 "",
+		"missing.Sku":                   "",
+	} {
+		if actual := NewField(name, renderArgs).Value(); actual != expected {
+			t.Errorf("Value of %s: (expected) %v != %v (actual)", name, expected, actual)
+		}
+	}
+
+	if id := NewField("order.Items[0].Options[color]", renderArgs).Id(); id != "order_Items_0_Options_color" {
+		t.Errorf("Unexpected field id: %s", id)
+	}
+}
+
+type fieldAccount struct {
+	*Customer
+}
+
+func TestFieldValueNilEmbedded(t *testing.T) {
+	account := &fieldAccount{}
+	renderArgs := map[string]interface{}{"errors": map[string]*ValidationError{}, "account": account}
+	if actual := NewField("account.Email", renderArgs).Value(); actual != "" {
+		t.Errorf("Expected an empty value, got %v", actual)
+	}
+	if account.Customer != nil {
+		t.Errorf("Expected the embedded pointer to be left nil, got %v", account.Customer)
+	}
+}
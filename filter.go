@@ -1,9 +1,19 @@
 package revel
 
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
 type Filter func(c *Controller, filterChain []Filter)
 
 // Filters is the default set of global filters.
-// It may be set by the application on initialization.
+// It may be set by the application on initialization, or in app.conf as a
+// comma separated list of registered filter names, e.g.
+//   filters = panic,router,filterconfig,params,session,flash,action
 var Filters = []Filter{
 	PanicFilter,             // Recover from panics and display an error page instead.
 	RouterFilter,            // Use the routing table to select the right Action.
@@ -23,3 +33,128 @@ var (
 	NilFilter = func(_ *Controller, _ []Filter) {}
 	NilChain  = []Filter{NilFilter}
 )
+
+// Map from name to the filters registered with RegisterFilter.
+var namedFilters = make(map[string]Filter)
+
+func init() {
+	RegisterFilter("panic", PanicFilter)
+	RegisterFilter("secureheaders", SecureHeadersFilter)
+	RegisterFilter("cors", CORSFilter)
+	RegisterFilter("methodoverride", HttpMethodOverrideFilter)
+	RegisterFilter("router", RouterFilter)
+	RegisterFilter("filterconfig", FilterConfiguringFilter)
+	RegisterFilter("timeout", TimeoutFilter)
+	RegisterFilter("params", ParamsFilter)
+	RegisterFilter("session", SessionFilter)
+	RegisterFilter("csrf", CSRFFilter)
+	RegisterFilter("flash", FlashFilter)
+	RegisterFilter("validation", ValidationFilter)
+	RegisterFilter("i18n", I18nFilter)
+	RegisterFilter("interceptor", InterceptorFilter)
+	RegisterFilter("compress", CompressFilter)
+	RegisterFilter("action", ActionInvoker)
+
+	OnAppStart(func() {
+		if err := loadFilterChain(Config); err != nil {
+			ERROR.Fatalln("filters invalid:", err)
+		}
+	})
+}
+
+// loadFilterChain sets Filters to the chain of "filters" in the config, if
+// any. The overrides made with FilterController and FilterAction, e.g. in the
+// init functions of the app, are then built again from the new chain.
+func loadFilterChain(conf *MergedConfig) error {
+	names := conf.StringDefault("filters", "")
+	if names == "" {
+		return nil
+	}
+	filters, err := parseFilterChain(names)
+	if err != nil {
+		return err
+	}
+	// Keep the WatchFilter added in dev mode.
+	if len(Filters) > 0 && FilterEq(Filters[0], WatchFilter) {
+		filters = append([]Filter{WatchFilter}, filters...)
+	}
+	Filters = filters
+	rebuildFilterOverrides()
+	return nil
+}
+
+// RegisterFilter makes a filter available under the given name, for use in
+// app.conf, and shows it under that name in the filter chains. Filters made
+// by a function, e.g. RateLimitFilter, should be registered at most once.
+func RegisterFilter(name string, f Filter) {
+	namedFilters[name] = f
+}
+
+// FilterName returns the name the filter was registered with, or else the name
+// of its function.
+func FilterName(f Filter) string {
+	if name, ok := registeredFilterName(f); ok {
+		return name
+	}
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "?"
+}
+
+// registeredFilterName returns the name the filter was registered with, the
+// first one if it was registered several times. The closures made by the same
+// function share their code, so filters are matched by their func value.
+func registeredFilterName(f Filter) (string, bool) {
+	var (
+		id    = filterId(f)
+		found string
+		ok    bool
+	)
+	for name, named := range namedFilters {
+		if filterId(named) == id && (!ok || name < found) {
+			found, ok = name, true
+		}
+	}
+	return found, ok
+}
+
+// filterId returns the pointer to the func value of the filter, which is the
+// same for all the references to a function, but differs between closures.
+func filterId(f Filter) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&f))
+}
+
+// FilterChain returns the filters run for the given action, e.g. "App.Index",
+// after the overrides of its controller and of the action are applied.
+func FilterChain(action string) []Filter {
+	controllerName := action
+	if dot := strings.Index(action, "."); dot != -1 {
+		controllerName = action[:dot]
+	}
+
+	chain := make([]Filter, len(Filters))
+	copy(chain, Filters)
+	for i, f := range Filters {
+		if FilterEq(f, FilterConfiguringFilter) {
+			if override := getOverrideChain(controllerName, action); override != nil {
+				chain = append(chain[:i+1], override...)
+			}
+			break
+		}
+	}
+	return chain
+}
+
+// parseFilterChain returns the filters of a comma separated list of names.
+func parseFilterChain(names string) ([]Filter, error) {
+	var filters []Filter
+	for _, name := range splitConfigList(names) {
+		f, ok := namedFilters[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %s", name)
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
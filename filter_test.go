@@ -0,0 +1,106 @@
+package revel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFilterChain(t *testing.T) {
+	filters, err := parseFilterChain("panic, router,filterconfig,csrf,action")
+	expected := []Filter{PanicFilter, RouterFilter, FilterConfiguringFilter, CSRFFilter, ActionInvoker}
+	if err != nil || len(filters) != len(expected) || !filterSliceEqual(filters, expected) {
+		t.Errorf("Unexpected chain %v: %v", filters, err)
+	}
+
+	if _, err = parseFilterChain("panic,unknown"); err == nil {
+		t.Errorf("Expected an error for an unknown filter")
+	}
+}
+
+func TestFilterName(t *testing.T) {
+	if name := FilterName(CSRFFilter); name != "csrf" {
+		t.Errorf("Expected csrf, got %s", name)
+	}
+	if name := FilterName(NilFilter); name == "" || name == "?" {
+		t.Errorf("Expected the function name of an unregistered filter, got %s", name)
+	}
+}
+
+func TestFilterEqByName(t *testing.T) {
+	var (
+		login = RateLimitFilter(RateLimit{Limit: 5, Window: time.Minute})
+		api   = RateLimitFilter(RateLimit{Limit: 100, Window: time.Minute})
+	)
+	RegisterFilter("ratelimit.login", login)
+	RegisterFilter("ratelimit.api", api)
+	defer func() {
+		delete(namedFilters, "ratelimit.login")
+		delete(namedFilters, "ratelimit.api")
+	}()
+
+	if !FilterEq(login, login) || FilterEq(login, api) {
+		t.Errorf("Expected the registered filters to be compared by name")
+	}
+	if name := FilterName(api); name != "ratelimit.api" {
+		t.Errorf("Expected ratelimit.api, got %s", name)
+	}
+	if FilterEq(login, RateLimitFilter(RateLimit{Limit: 5, Window: time.Minute})) {
+		t.Errorf("Expected an unregistered filter not to equal a registered one")
+	}
+	if !FilterEq(NilFilter, NilFilter) || FilterEq(NilFilter, CSRFFilter) {
+		t.Errorf("Expected the unregistered filters to be compared by function")
+	}
+
+	chain := FilterConfigurator{}.rmFilter(login, []Filter{PanicFilter, login, api, ActionInvoker})
+	if expected := []Filter{PanicFilter, api, ActionInvoker}; len(chain) != len(expected) ||
+		!filterSliceEqual(chain, expected) {
+		t.Errorf("Expected only the login rate limit to be removed, got %v", chain)
+	}
+}
+
+type FilterChainController struct{}
+
+func (c FilterChainController) Foo() {}
+
+func TestFilterChain(t *testing.T) {
+	oldFilters := Filters
+	defer func() {
+		Filters = oldFilters
+		delete(filterOverrides, "FilterChainController.Foo")
+	}()
+
+	Filters = []Filter{PanicFilter, FilterConfiguringFilter, SessionFilter, ActionInvoker}
+	FilterAction(FilterChainController.Foo).Remove(SessionFilter)
+
+	chain := FilterChain("FilterChainController.Foo")
+	if expected := []Filter{PanicFilter, FilterConfiguringFilter, ActionInvoker}; len(chain) != len(expected) ||
+		!filterSliceEqual(chain, expected) {
+		t.Errorf("Unexpected chain for Foo: %v", chain)
+	}
+	if chain = FilterChain("FilterChainController.Bar"); len(chain) != len(Filters) || !filterSliceEqual(chain, Filters) {
+		t.Errorf("Expected the default chain for Bar, got %v", chain)
+	}
+}
+
+func TestFilterChainConfigWithOverride(t *testing.T) {
+	defer func(filters []Filter, overrides map[string][]Filter, ops []filterOverrideOp) {
+		Filters, filterOverrides, filterOverrideOps = filters, overrides, ops
+		applyFilterConfig()
+		Config.SetOption("filters", "")
+	}(Filters, filterOverrides, filterOverrideOps)
+	filterOverrides, filterOverrideOps = make(map[string][]Filter), nil
+
+	// The app overrides the action in its init, before the config is loaded.
+	Filters = []Filter{PanicFilter, FilterConfiguringFilter, SessionFilter, ActionInvoker}
+	FilterAction(FilterChainController.Foo).Remove(SessionFilter)
+
+	Config.SetOption("filters", "panic,filterconfig,session,csrf,action")
+	if err := loadFilterChain(Config); err != nil {
+		t.Fatal(err)
+	}
+	chain := FilterChain("FilterChainController.Foo")
+	if expected := []Filter{PanicFilter, FilterConfiguringFilter, CSRFFilter, ActionInvoker}; len(chain) != len(expected) ||
+		!filterSliceEqual(chain, expected) {
+		t.Errorf("Expected the override to apply to the configured chain, got %v", chain)
+	}
+}
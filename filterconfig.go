@@ -1,13 +1,28 @@
 package revel
 
 import (
+	"fmt"
+	"os"
+	"path"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // Map from "Controller" or "Controller.Method" to the Filter chain
 var filterOverrides = make(map[string][]Filter)
 
+// A change of a filter chain made with a FilterConfigurator.
+type filterOverrideOp struct {
+	conf FilterConfigurator
+	f    func([]Filter) []Filter
+}
+
+// The changes made with FilterConfigurators, in order, so that filterOverrides
+// can be built again when Filters changes.
+var filterOverrideOps []filterOverrideOp
+
 // FilterConfigurator allows the developer configure the filter chain on a
 // per-controller or per-action basis.  The filter configuration is applied by
 // the FilterConfiguringFilter, which is itself a filter stage.  For example,
@@ -156,11 +171,11 @@ func (conf FilterConfigurator) insertFilter(insert Filter, where When, target Fi
 }
 
 // getChain returns the filter chain that applies to the given controller or
-// action.  If no overrides are configured, then a copy of the default filter
-// chain is returned.
-func (conf FilterConfigurator) getChain() []Filter {
+// action in the given overrides.  If no overrides are configured, then a copy
+// of the default filter chain is returned.
+func (conf FilterConfigurator) getChain(overrides map[string][]Filter) []Filter {
 	var filters []Filter
-	if filters = getOverrideChain(conf.controllerName, conf.key); filters == nil {
+	if filters = lookupOverrideChain(overrides, conf.controllerName, conf.key); filters == nil {
 		// The override starts with all filters after FilterConfiguringFilter
 		for i, f := range Filters {
 			if FilterEq(f, FilterConfiguringFilter) {
@@ -176,25 +191,52 @@ func (conf FilterConfigurator) getChain() []Filter {
 	return filters
 }
 
-// apply applies the given functional change to the filter overrides.
-// No other function modifies the filterOverrides map.
+// apply applies the given functional change to the filter overrides, and
+// then applies the [filters] config on top of them again.
 func (conf FilterConfigurator) apply(f func([]Filter) []Filter) {
+	filterOverrideOps = append(filterOverrideOps, filterOverrideOp{conf, f})
+	conf.applyTo(filterOverrides, f)
+	applyFilterConfig()
+}
+
+// rebuildFilterOverrides builds filterOverrides again from Filters, e.g. once
+// it is set from the config, and then applies the [filters] config.
+func rebuildFilterOverrides() {
+	filterOverrides = make(map[string][]Filter)
+	for _, op := range filterOverrideOps {
+		op.conf.applyTo(filterOverrides, op.f)
+	}
+	applyFilterConfig()
+}
+
+// applyTo applies the given functional change to the given overrides.
+// No other function than apply and rebuildFilterOverrides modifies the
+// filterOverrides map.
+func (conf FilterConfigurator) applyTo(overrides map[string][]Filter, f func([]Filter) []Filter) {
 	// Updates any actions that have had their filters overridden, if this is a
 	// Controller configurator.
 	if conf.controllerName == conf.key {
-		for k, v := range filterOverrides {
+		for k, v := range overrides {
 			if strings.HasPrefix(k, conf.controllerName+".") {
-				filterOverrides[k] = f(v)
+				overrides[k] = f(v)
 			}
 		}
 	}
 
 	// Update the Controller or Action overrides.
-	filterOverrides[conf.key] = f(conf.getChain())
+	overrides[conf.key] = f(conf.getChain(overrides))
 }
 
-// FilterEq returns true if the two filters reference the same filter.
+// FilterEq returns true if the two filters reference the same filter. The
+// filters registered with RegisterFilter are compared by name, so that the
+// filters made by the same function, e.g. RateLimitFilter, are told apart,
+// and the others by function.
 func FilterEq(a, b Filter) bool {
+	nameA, okA := registeredFilterName(a)
+	nameB, okB := registeredFilterName(b)
+	if okA || okB {
+		return okA && okB && nameA == nameB
+	}
 	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
 }
 
@@ -208,13 +250,188 @@ func FilterConfiguringFilter(c *Controller, fc []Filter) {
 	fc[0](c, fc[1:])
 }
 
-// getOverrideChain retrieves the overrides for the action that is set
+// getOverrideChain retrieves the overrides for the action that is set,
+// including those of the [filters] config.
 func getOverrideChain(controllerName, action string) []Filter {
-	if newChain, ok := filterOverrides[action]; ok {
+	configuredFilterOverridesMutex.RLock()
+	overrides := configuredFilterOverrides
+	configuredFilterOverridesMutex.RUnlock()
+
+	if overrides != nil {
+		return lookupOverrideChain(overrides, controllerName, action)
+	}
+	return lookupOverrideChain(filterOverrides, controllerName, action)
+}
+
+func lookupOverrideChain(overrides map[string][]Filter, controllerName, action string) []Filter {
+	if newChain, ok := overrides[action]; ok {
 		return newChain
 	}
-	if newChain, ok := filterOverrides[controllerName]; ok {
+	if newChain, ok := overrides[controllerName]; ok {
 		return newChain
 	}
 	return nil
 }
+
+// A change of a filter chain made in the [filters] section of app.conf.
+type filterConfigOp struct {
+	conf   FilterConfigurator
+	add    bool // or remove
+	filter Filter
+}
+
+var (
+	// The changes made in the [filters] sections of app.conf.
+	filterConfigOps []filterConfigOp
+
+	// filterOverrides with filterConfigOps applied, or nil if there are none.
+	// It is replaced when app.conf is reloaded, while requests read it.
+	configuredFilterOverrides      map[string][]Filter
+	configuredFilterOverridesMutex sync.RWMutex
+)
+
+func init() {
+	OnAppStart(func() {
+		if err := loadFilterConfig(Config); err != nil {
+			ERROR.Fatalln("[filters] invalid:", err)
+		}
+
+		if MainWatcher != nil && Config.BoolDefault("watch.filters", true) {
+			for _, confPath := range ConfPaths {
+				if _, err := os.Stat(path.Join(confPath, "app.conf")); err == nil {
+					MainWatcher.Listen(filterConfigWatcher{}, path.Join(confPath, "app.conf"))
+					break
+				}
+			}
+		}
+	})
+}
+
+// loadFilterConfig reads the per-controller and per-action filter changes of
+// the [filters] section of the config, followed by those of the section of
+// the run mode, e.g. [filters.prod]:
+//
+//   [filters]
+//   App.Login = -csrf,+ratelimit
+//   Api = -session,-flash
+//
+// Filters are removed with -name and added (before the ActionInvoker) with
+// +name, using the names given with RegisterFilter. The changes are applied
+// on top of those made with FilterController and FilterAction.
+func loadFilterConfig(conf *MergedConfig) error {
+	var ops []filterConfigOp
+	for _, section := range []string{"filters", "filters." + RunMode} {
+		if !conf.HasSection(section) {
+			continue
+		}
+
+		// Change the controllers first, as their changes also apply to the
+		// actions overridden before.
+		keys, _ := conf.Raw().SectionOptions(section)
+		sort.Sort(byControllerFirst(keys))
+		for _, key := range keys {
+			configurator, err := filterConfiguratorByName(key)
+			if err != nil {
+				return err
+			}
+			value, _ := conf.Raw().String(section, key)
+			for _, item := range splitConfigList(stripQuotes(value)) {
+				if len(item) < 2 || (item[0] != '+' && item[0] != '-') {
+					return fmt.Errorf("%s: expected +filter or -filter, got %s", key, item)
+				}
+				f, ok := namedFilters[item[1:]]
+				if !ok {
+					return fmt.Errorf("%s: unknown filter %s", key, item[1:])
+				}
+				ops = append(ops, filterConfigOp{configurator, item[0] == '+', f})
+			}
+		}
+	}
+
+	filterConfigOps = ops
+	applyFilterConfig()
+	return nil
+}
+
+// applyFilterConfig computes configuredFilterOverrides from filterOverrides
+// and the [filters] config.
+func applyFilterConfig() {
+	if len(filterConfigOps) == 0 {
+		setConfiguredFilterOverrides(nil)
+		return
+	}
+
+	overrides := make(map[string][]Filter, len(filterOverrides))
+	for key, chain := range filterOverrides {
+		overrides[key] = append([]Filter(nil), chain...)
+	}
+	for _, op := range filterConfigOps {
+		op := op
+		op.conf.applyTo(overrides, func(fc []Filter) []Filter {
+			if op.add {
+				return op.conf.addFilter(op.filter, fc)
+			}
+			return op.conf.rmFilter(op.filter, fc)
+		})
+	}
+	setConfiguredFilterOverrides(overrides)
+}
+
+func setConfiguredFilterOverrides(overrides map[string][]Filter) {
+	configuredFilterOverridesMutex.Lock()
+	defer configuredFilterOverridesMutex.Unlock()
+
+	configuredFilterOverrides = overrides
+}
+
+// filterConfiguratorByName returns the configurator of a "Controller" or
+// "Controller.Action" (case insensitive).
+func filterConfiguratorByName(name string) (FilterConfigurator, error) {
+	controllerName, methodName := name, ""
+	if dot := strings.Index(name, "."); dot != -1 {
+		controllerName, methodName = name[:dot], name[dot+1:]
+	}
+
+	controllerType, ok := controllers[strings.ToLower(controllerName)]
+	if !ok {
+		return FilterConfigurator{}, fmt.Errorf("unknown controller %s", controllerName)
+	}
+	if methodName == "" {
+		return newFilterConfigurator(controllerType.Type.Name(), ""), nil
+	}
+	method := controllerType.Method(methodName)
+	if method == nil {
+		return FilterConfigurator{}, fmt.Errorf("unknown action %s", name)
+	}
+	return newFilterConfigurator(controllerType.Type.Name(), method.Name), nil
+}
+
+type byControllerFirst []string
+
+func (a byControllerFirst) Len() int      { return len(a) }
+func (a byControllerFirst) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byControllerFirst) Less(i, j int) bool {
+	iAction, jAction := strings.Contains(a[i], "."), strings.Contains(a[j], ".")
+	if iAction != jAction {
+		return jAction
+	}
+	return a[i] < a[j]
+}
+
+// filterConfigWatcher reloads the [filters] config when app.conf changes.
+type filterConfigWatcher struct{}
+
+func (w filterConfigWatcher) Refresh() *Error {
+	conf, err := LoadConfig("app.conf")
+	if err == nil {
+		err = loadFilterConfig(conf)
+	}
+	if err != nil {
+		return &Error{
+			Title:       "Filter Config Error",
+			Path:        "app.conf",
+			Description: err.Error(),
+		}
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package revel
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/robfig/config"
+)
 
 type FakeController struct{}
 
@@ -135,3 +139,90 @@ func filterSliceEqual(a, e []Filter) bool {
 func getOverride(methodName string) []Filter {
 	return getOverrideChain("FakeController", "FakeController."+methodName)
 }
+
+type FilterConfController struct{}
+
+func (c FilterConfController) Login() {}
+func (c FilterConfController) Index() {}
+
+func TestFilterConfig(t *testing.T) {
+	oldFilters := Filters
+	defer func() {
+		Filters = oldFilters
+		delete(filterOverrides, "FilterConfController.Index")
+		filterConfigOps = nil
+		setConfiguredFilterOverrides(nil)
+	}()
+	RegisterController((*FilterConfController)(nil), []*MethodType{{Name: "Login"}, {Name: "Index"}})
+
+	Filters = []Filter{
+		RouterFilter,
+		FilterConfiguringFilter,
+		SessionFilter,
+		CSRFFilter,
+		ActionInvoker,
+	}
+	FilterAction(FilterConfController.Index).Add(NilFilter)
+
+	raw := config.NewDefault()
+	raw.AddOption("filters", "filterconfcontroller", "-session")
+	raw.AddOption("filters", "FilterConfController.login", "-csrf,+compress")
+	if err := loadFilterConfig(&MergedConfig{raw, ""}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Filter{CompressFilter, ActionInvoker}
+	if actual := getOverrideChain("FilterConfController", "FilterConfController.Login"); len(actual) != len(expected) ||
+		!filterSliceEqual(actual, expected) {
+		t.Errorf("Unexpected chain for Login: %v", actual)
+	}
+
+	// The overrides made in code are kept, and changed by the controller config.
+	expected = []Filter{CSRFFilter, NilFilter, ActionInvoker}
+	if actual := getOverrideChain("FilterConfController", "FilterConfController.Index"); len(actual) != len(expected) ||
+		!filterSliceEqual(actual, expected) {
+		t.Errorf("Unexpected chain for Index: %v", actual)
+	}
+	if actual := filterOverrides["FilterConfController.Index"]; len(actual) != 4 {
+		t.Errorf("Expected the code overrides to be unchanged, got %v", actual)
+	}
+
+	raw.AddOption("filters", "FilterConfController.login", "-unknown")
+	if err := loadFilterConfig(&MergedConfig{raw, ""}); err == nil {
+		t.Errorf("Expected an error for an unknown filter")
+	}
+}
+
+func TestFilterConfigReload(t *testing.T) {
+	oldFilters := Filters
+	defer func() {
+		Filters = oldFilters
+		filterConfigOps = nil
+		setConfiguredFilterOverrides(nil)
+	}()
+	RegisterController((*FilterConfController)(nil), []*MethodType{{Name: "Login"}, {Name: "Index"}})
+	Filters = []Filter{RouterFilter, FilterConfiguringFilter, SessionFilter, ActionInvoker}
+
+	raw := config.NewDefault()
+	raw.AddOption("filters", "FilterConfController.login", "-session")
+
+	// Requests read the overrides while app.conf is reloaded.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			if err := loadFilterConfig(&MergedConfig{raw, ""}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		getOverrideChain("FilterConfController", "FilterConfController.Login")
+	}
+	<-done
+
+	if actual := getOverrideChain("FilterConfController", "FilterConfController.Login"); len(actual) != 1 {
+		t.Errorf("Unexpected chain for Login: %v", actual)
+	}
+}
@@ -1,38 +1,79 @@
 package revel
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 )
 
+// The levels of the flash messages. Any other level may be used with
+// Flash.Add.
+const (
+	FLASH_ERROR   = "error"
+	FLASH_WARNING = "warning"
+	FLASH_INFO    = "info"
+	FLASH_SUCCESS = "success"
+
+	// The flash key of the list of messages, in the order they were added.
+	FLASH_MESSAGES_KEY = "_messages"
+)
+
+// A FlashMessage is a message added to the Flash with a level.
+type FlashMessage struct {
+	Level   string `json:"l"`
+	Message string `json:"m"`
+}
+
 // Flash represents a cookie that is overwritten on each request.
 // It allows data to be stored across one page at a time.
 // This is commonly used to implement success or error messages.
 // E.g. the Post/Redirect/Get pattern:
 // http://en.wikipedia.org/wiki/Post/Redirect/Get
+//
+// Like the session, the cookie is signed, or encrypted, so that it cannot
+// be forged.
 type Flash struct {
 	Data, Out map[string]string
 }
 
-// Error serializes the given msg and args to an "error" key within
-// the Flash cookie.
-func (f Flash) Error(msg string, args ...interface{}) {
-	if len(args) == 0 {
-		f.Out["error"] = msg
-	} else {
-		f.Out["error"] = fmt.Sprintf(msg, args...)
+// Add adds the message formatted from msg and args with the given level.
+// All the messages are kept in order, see Messages and the flashes template
+// helper, and the last one of each level is also available as
+// {{.flash.level}}.
+func (f Flash) Add(level, msg string, args ...interface{}) {
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
 	}
+	messages := decodeFlashMessages(f.Out[FLASH_MESSAGES_KEY])
+	f.Out[FLASH_MESSAGES_KEY] = encodeFlashMessages(append(messages, FlashMessage{level, msg}))
+	f.Out[level] = msg
+}
+
+// Error adds the given msg and args with the "error" level.
+func (f Flash) Error(msg string, args ...interface{}) {
+	f.Add(FLASH_ERROR, msg, args...)
+}
+
+// Warning adds the given msg and args with the "warning" level.
+func (f Flash) Warning(msg string, args ...interface{}) {
+	f.Add(FLASH_WARNING, msg, args...)
+}
+
+// Info adds the given msg and args with the "info" level.
+func (f Flash) Info(msg string, args ...interface{}) {
+	f.Add(FLASH_INFO, msg, args...)
 }
 
-// Success serializes the given msg and args to a "success" key within
-// the Flash cookie.
+// Success adds the given msg and args with the "success" level.
 func (f Flash) Success(msg string, args ...interface{}) {
-	if len(args) == 0 {
-		f.Out["success"] = msg
-	} else {
-		f.Out["success"] = fmt.Sprintf(msg, args...)
-	}
+	f.Add(FLASH_SUCCESS, msg, args...)
+}
+
+// Messages returns the messages flashed by the previous request, in order,
+// only of the given levels if any.
+func (f Flash) Messages(levels ...string) []FlashMessage {
+	return flashMessages(f.Data, levels...)
 }
 
 // Keep messages of the Flash cookie
@@ -45,12 +86,27 @@ func (f Flash) Keep(names ...string) {
 				}
 			}
 		}
+		f.keepMessages(names...)
 	} else {
 		for key, value := range f.Data {
-			if _, has := f.Out[key]; !has {
+			if _, has := f.Out[key]; !has && key != FLASH_MESSAGES_KEY {
 				f.Out[key] = value
 			}
 		}
+		f.keepMessages()
+	}
+}
+
+// keepMessages puts the messages of the previous request, of the given levels
+// if any, before those added by this one.
+func (f Flash) keepMessages(levels ...string) {
+	kept := decodeFlashMessages(f.Data[FLASH_MESSAGES_KEY])
+	if len(levels) > 0 {
+		kept = filterFlashMessages(kept, levels)
+	}
+	if len(kept) > 0 {
+		messages := append(kept, decodeFlashMessages(f.Out[FLASH_MESSAGES_KEY])...)
+		f.Out[FLASH_MESSAGES_KEY] = encodeFlashMessages(messages)
 	}
 }
 
@@ -68,13 +124,10 @@ func FlashFilter(c *Controller, fc []Filter) {
 	for key, value := range c.Flash.Out {
 		flashValue += "\x00" + key + ":" + value + "\x00"
 	}
-	c.SetCookie(&http.Cookie{
-		Name:     CookiePrefix + "_FLASH",
-		Value:    url.QueryEscape(flashValue),
-		HttpOnly: CookieHttpOnly,
-		Secure:   CookieSecure,
-		Path:     "/",
-	})
+	if flashValue != "" {
+		flashValue = protectCookieValue(flashCookiePurpose, url.QueryEscape(flashValue))
+	}
+	c.SetCookie(NewCookie(CookiePrefix+"_FLASH", flashValue))
 }
 
 // restoreFlash deserializes a Flash cookie struct from a request.
@@ -83,10 +136,76 @@ func restoreFlash(req *http.Request) Flash {
 		Data: make(map[string]string),
 		Out:  make(map[string]string),
 	}
-	if cookie, err := req.Cookie(CookiePrefix + "_FLASH"); err == nil {
-		ParseKeyValueCookie(cookie.Value, func(key, val string) {
+	if cookie, err := req.Cookie(CookiePrefix + "_FLASH"); err == nil && cookie.Value != "" {
+		value, ok := unprotectCookieValue(flashCookiePurpose, cookie.Value)
+		if !ok {
+			INFO.Println("Flash cookie signature failed")
+			return flash
+		}
+		ParseKeyValueCookie(value, func(key, val string) {
 			flash.Data[key] = val
 		})
 	}
 	return flash
 }
+
+// flashesHelper returns the flash messages in the given render args, for use
+// in templates:
+//   {{range flashes .}}<div class="alert-{{.Level}}">{{.Message}}</div>{{end}}
+func flashesHelper(renderArgs map[string]interface{}, levels ...string) []FlashMessage {
+	data, _ := renderArgs["flash"].(map[string]string)
+	return flashMessages(data, levels...)
+}
+
+// flashMessages returns the messages of the flash data, of the given levels if
+// any. The messages of the standard levels set directly in the data, rather
+// than with Flash.Add, are also returned.
+func flashMessages(data map[string]string, levels ...string) []FlashMessage {
+	messages, ok := data[FLASH_MESSAGES_KEY]
+	if !ok {
+		var legacy []FlashMessage
+		for _, level := range []string{FLASH_ERROR, FLASH_WARNING, FLASH_INFO, FLASH_SUCCESS} {
+			if message, ok := data[level]; ok {
+				legacy = append(legacy, FlashMessage{level, message})
+			}
+		}
+		return filterFlashMessages(legacy, levels)
+	}
+	return filterFlashMessages(decodeFlashMessages(messages), levels)
+}
+
+// filterFlashMessages returns the messages of the given levels, or all of
+// them if there are none.
+func filterFlashMessages(messages []FlashMessage, levels []string) []FlashMessage {
+	if len(levels) == 0 {
+		return messages
+	}
+	var filtered []FlashMessage
+	for _, message := range messages {
+		for _, level := range levels {
+			if message.Level == level {
+				filtered = append(filtered, message)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func encodeFlashMessages(messages []FlashMessage) string {
+	data, err := json.Marshal(messages)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func decodeFlashMessages(value string) []FlashMessage {
+	var messages []FlashMessage
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &messages); err != nil {
+			WARN.Println("Invalid flash messages:", err)
+		}
+	}
+	return messages
+}
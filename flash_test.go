@@ -0,0 +1,126 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// flashTester runs the FlashFilter for a request with the given cookies, and
+// the action, and returns the controller and the flash cookie it set.
+func flashTester(cookies []*http.Cookie, action func(c *Controller)) (*Controller, *http.Cookie) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	for _, cookie := range cookies {
+		httpRequest.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	c := NewController(NewRequest(httpRequest), NewResponse(recorder))
+
+	FlashFilter(c, []Filter{func(c *Controller, _ []Filter) {
+		action(c)
+	}})
+
+	for _, cookie := range (&http.Response{Header: recorder.Header()}).Cookies() {
+		if cookie.Name == CookiePrefix+"_FLASH" {
+			return c, cookie
+		}
+	}
+	return c, nil
+}
+
+func TestFlashMessages(t *testing.T) {
+	_, cookie := flashTester(nil, func(c *Controller) {
+		c.Flash.Error("First %d", 1)
+		c.Flash.Info("Second")
+		c.Flash.Error("Third")
+		c.Flash.Add("notice", "Fourth")
+	})
+
+	c, _ := flashTester([]*http.Cookie{cookie}, func(c *Controller) {})
+	expected := []FlashMessage{
+		{FLASH_ERROR, "First 1"},
+		{FLASH_INFO, "Second"},
+		{FLASH_ERROR, "Third"},
+		{"notice", "Fourth"},
+	}
+	if messages := c.Flash.Messages(); !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+	if c.Flash.Data[FLASH_ERROR] != "Third" {
+		t.Errorf("Expected the last error as flash.error, got %q", c.Flash.Data[FLASH_ERROR])
+	}
+	if messages := flashesHelper(c.RenderArgs, FLASH_INFO, "notice"); !reflect.DeepEqual(messages,
+		[]FlashMessage{expected[1], expected[3]}) {
+		t.Errorf("Unexpected messages of the info and notice levels: %v", messages)
+	}
+}
+
+func TestFlashKeep(t *testing.T) {
+	_, cookie := flashTester(nil, func(c *Controller) {
+		c.Flash.Error("Kept")
+		c.Flash.Success("Dropped")
+	})
+	_, cookie = flashTester([]*http.Cookie{cookie}, func(c *Controller) {
+		c.Flash.Keep(FLASH_ERROR)
+		c.Flash.Info("New")
+	})
+
+	c, _ := flashTester([]*http.Cookie{cookie}, func(c *Controller) {})
+	expected := []FlashMessage{{FLASH_ERROR, "Kept"}, {FLASH_INFO, "New"}}
+	if messages := c.Flash.Messages(); !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestFlashSignature(t *testing.T) {
+	withSecrets("secret", nil, func() {
+		_, cookie := flashTester(nil, func(c *Controller) {
+			c.Flash.Success("Saved")
+		})
+
+		c, _ := flashTester([]*http.Cookie{cookie}, func(c *Controller) {})
+		if c.Flash.Data[FLASH_SUCCESS] != "Saved" {
+			t.Fatalf("Expected the signed flash to be restored, got %v", c.Flash.Data)
+		}
+
+		forged := &http.Cookie{Name: cookie.Name, Value: "-%00error%3AForged%00"}
+		if c, _ = flashTester([]*http.Cookie{forged}, func(c *Controller) {}); len(c.Flash.Data) != 0 {
+			t.Errorf("Expected a forged flash to be ignored, got %v", c.Flash.Data)
+		}
+	})
+}
+
+func TestFlashNotValidAsSession(t *testing.T) {
+	withSecrets("secret", nil, func() {
+		// The flashed params of a request may look like a session.
+		_, cookie := flashTester(nil, func(c *Controller) {
+			c.Flash.Out[TIMESTAMP_KEY] = "session"
+			c.Flash.Out[SESSION_USER_KEY] = "1"
+		})
+		if cookie == nil || cookie.Value == "" {
+			t.Fatal("Expected a flash cookie")
+		}
+		if session := getSessionFromCookie(&http.Cookie{Value: cookie.Value}); len(session) != 0 {
+			t.Errorf("Expected the flash cookie to be rejected as a session, got %v", session)
+		}
+	})
+}
+
+func TestEncryptedFlashNotValidAsSession(t *testing.T) {
+	withSecrets("secret", nil, func() {
+		CookieEncrypt = true
+		defer func() { CookieEncrypt = false }()
+
+		_, cookie := flashTester(nil, func(c *Controller) {
+			c.Flash.Out[TIMESTAMP_KEY] = "session"
+			c.Flash.Out[SESSION_USER_KEY] = "1"
+		})
+		if c, _ := flashTester([]*http.Cookie{cookie}, func(c *Controller) {}); c.Flash.Data[SESSION_USER_KEY] != "1" {
+			t.Fatalf("Expected the encrypted flash to be restored, got %v", c.Flash.Data)
+		}
+		if session := getSessionFromCookie(&http.Cookie{Value: cookie.Value}); len(session) != 0 {
+			t.Errorf("Expected the encrypted flash cookie to be rejected as a session, got %v", session)
+		}
+	})
+}
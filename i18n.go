@@ -39,23 +39,36 @@ func MessageLanguages() []string {
 //
 // When either an unknown locale or message is detected, a specially formatted string is returned.
 func Message(locale, message string, args ...interface{}) string {
+	value, found := findMessage(locale, message, args...)
+	if !found {
+		WARN.Printf("Unknown message '%s' for locale '%s'", message, locale)
+		return fmt.Sprintf(unknownValueFormat, message)
+	}
+	return value
+}
+
+// Look up the given message for the locale, falling back to the default
+// language when the locale is not supported. Reports whether the message was found.
+func findMessage(locale, message string, args ...interface{}) (string, bool) {
 	language, region := parseLocale(locale)
 
 	messageConfig, knownLanguage := messages[language]
 	if !knownLanguage {
 		TRACE.Printf("Unsupported language for locale '%s' and message '%s', trying default language", locale, message)
 
-		if defaultLanguage, found := Config.String(defaultLanguageOption); found {
+		if Config == nil {
+			return "", false
+		} else if defaultLanguage, found := Config.String(defaultLanguageOption); found {
 			TRACE.Printf("Using default language '%s'", defaultLanguage)
 
 			messageConfig, knownLanguage = messages[defaultLanguage]
 			if !knownLanguage {
-				WARN.Printf("Unsupported default language for locale '%s' and message '%s'", defaultLanguage, message)
-				return fmt.Sprintf(unknownValueFormat, message)
+				TRACE.Printf("Unsupported default language for locale '%s' and message '%s'", defaultLanguage, message)
+				return "", false
 			}
 		} else {
-			WARN.Printf("Unable to find default language option (%s); messages for unsupported locales will never be translated", defaultLanguageOption)
-			return fmt.Sprintf(unknownValueFormat, message)
+			TRACE.Printf("Unable to find default language option (%s); messages for unsupported locales will never be translated", defaultLanguageOption)
+			return "", false
 		}
 	}
 
@@ -63,8 +76,7 @@ func Message(locale, message string, args ...interface{}) string {
 	// try to resolve message in DEFAULT if it did not find it in the given section.
 	value, error := messageConfig.String(region, message)
 	if error != nil {
-		WARN.Printf("Unknown message '%s' for locale '%s'", message, locale)
-		return fmt.Sprintf(unknownValueFormat, message)
+		return "", false
 	}
 
 	if len(args) > 0 {
@@ -72,7 +84,7 @@ func Message(locale, message string, args ...interface{}) string {
 		value = fmt.Sprintf(value, args...)
 	}
 
-	return value
+	return value, true
 }
 
 func parseLocale(locale string) (language, region string) {
@@ -84,12 +96,15 @@ func parseLocale(locale string) (language, region string) {
 	return locale, ""
 }
 
-// Recursively read and cache all available messages from all message files on the given path.
-func loadMessages(path string) {
+// Recursively read and cache all available messages from all message files on the given paths.
+// Messages from later paths override those of earlier ones for the same locale.
+func loadMessages(paths ...string) {
 	messages = make(map[string]*config.Config)
 
-	if error := filepath.Walk(path, loadMessageFile); error != nil && !os.IsNotExist(error) {
-		ERROR.Println("Error reading messages files:", error)
+	for _, path := range paths {
+		if error := filepath.Walk(path, loadMessageFile); error != nil && !os.IsNotExist(error) {
+			ERROR.Println("Error reading messages files:", error)
+		}
 	}
 }
 
@@ -137,7 +152,11 @@ func parseLocaleFromFileName(file string) string {
 
 func init() {
 	OnAppStart(func() {
-		loadMessages(filepath.Join(BasePath, messageFilesDirectory))
+		// Revel's own messages (e.g. validation errors) come first so that the
+		// application can override them.
+		loadMessages(
+			filepath.Join(RevelPath, messageFilesDirectory),
+			filepath.Join(BasePath, messageFilesDirectory))
 	})
 }
 
@@ -2,7 +2,11 @@ package revel
 
 import (
 	"log"
+	"net/http"
+	"path"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 // An "interceptor" is functionality invoked by the framework BEFORE or AFTER
@@ -18,7 +22,11 @@ import (
 // in the AFTER case it is possible that a further interceptor could emit its
 // own Result.
 //
-// Interceptors are called in the order that they are added.
+// PANIC and FINALLY interceptors find the error recovered from a panic in
+// Controller.PanicError.
+//
+// Interceptors are called by decreasing priority, see InterceptPriority, and
+// those of the same priority in the order that they are added.
 //
 // ***
 //
@@ -57,9 +65,54 @@ type Interception struct {
 	function InterceptorFunc
 	method   InterceptorMethod
 
+	// Interceptors with a higher Priority are called first. It defaults to 0.
+	Priority int
+
 	callable     reflect.Value
 	target       reflect.Type
 	interceptAll bool
+
+	actions       []string // Patterns of the actions intercepted, all if empty.
+	exceptActions []string // Patterns of the actions not intercepted.
+	methods       []string // HTTP methods intercepted, all if empty.
+}
+
+// An InterceptOption refines when an interceptor is called.
+type InterceptOption func(*Interception)
+
+// InterceptPriority sets the priority of the interceptor, e.g. so that an
+// authentication check runs before the interceptors opening a transaction,
+// whatever the order they are added in.
+func InterceptPriority(priority int) InterceptOption {
+	return func(i *Interception) {
+		i.Priority = priority
+	}
+}
+
+// OnlyActions limits the interceptor to the actions matching one of the given
+// patterns (see path.Match). Patterns containing a "." match the full action
+// name, e.g. "Hotels.Save*", and the others match the method name, e.g.
+// "Save*".
+func OnlyActions(patterns ...string) InterceptOption {
+	return func(i *Interception) {
+		i.actions = append(i.actions, patterns...)
+	}
+}
+
+// ExceptActions excludes the actions matching one of the given patterns, as
+// in OnlyActions, from the interceptor.
+func ExceptActions(patterns ...string) InterceptOption {
+	return func(i *Interception) {
+		i.exceptActions = append(i.exceptActions, patterns...)
+	}
+}
+
+// OnlyMethods limits the interceptor to requests with one of the given HTTP
+// methods, e.g. "POST".
+func OnlyMethods(methods ...string) InterceptOption {
+	return func(i *Interception) {
+		i.methods = append(i.methods, methods...)
+	}
 }
 
 // Perform the given interception.
@@ -89,6 +142,7 @@ func InterceptorFilter(c *Controller, fc []Filter) {
 	defer invokeInterceptors(FINALLY, c)
 	defer func() {
 		if err := recover(); err != nil {
+			c.PanicError = panicError(err)
 			invokeInterceptors(PANIC, c)
 			panic(err)
 		}
@@ -109,7 +163,7 @@ func invokeInterceptors(when When, c *Controller) {
 		app    = reflect.ValueOf(c.AppController)
 		result Result
 	)
-	for _, intc := range getInterceptors(when, app) {
+	for _, intc := range getInterceptors(when, c) {
 		resultValue := intc.Invoke(app)
 		if !resultValue.IsNil() {
 			result = resultValue.Interface().(Result)
@@ -130,37 +184,58 @@ var interceptors []*Interception
 // This can be applied to any Controller.
 // It must have the signature of:
 //   func example(c *revel.Controller) revel.Result
-func InterceptFunc(intc InterceptorFunc, when When, target interface{}) {
-	interceptors = append(interceptors, &Interception{
+// Options may set its priority and the actions it applies to, e.g.
+//   revel.InterceptFunc(checkUser, revel.BEFORE, &Hotels{},
+//     revel.InterceptPriority(10), revel.ExceptActions("Index", "Show"))
+func InterceptFunc(intc InterceptorFunc, when When, target interface{}, options ...InterceptOption) {
+	addInterception(&Interception{
 		When:         when,
 		function:     intc,
 		callable:     reflect.ValueOf(intc),
 		target:       reflect.TypeOf(target),
 		interceptAll: target == ALL_CONTROLLERS,
-	})
+	}, options)
 }
 
 // Install an interceptor method that applies to its own Controller.
 //   func (c AppController) example() revel.Result
 //   func (c *AppController) example() revel.Result
-func InterceptMethod(intc InterceptorMethod, when When) {
+// It accepts the same options as InterceptFunc.
+func InterceptMethod(intc InterceptorMethod, when When, options ...InterceptOption) {
 	methodType := reflect.TypeOf(intc)
 	if methodType.Kind() != reflect.Func || methodType.NumOut() != 1 || methodType.NumIn() != 1 {
 		log.Fatalln("Interceptor method should have signature like",
 			"'func (c *AppController) example() revel.Result' but was", methodType)
 	}
-	interceptors = append(interceptors, &Interception{
+	addInterception(&Interception{
 		When:     when,
 		method:   intc,
 		callable: reflect.ValueOf(intc),
 		target:   methodType.In(0),
+	}, options)
+}
+
+// addInterception applies the options and adds the interception, keeping the
+// interceptors sorted by priority.
+func addInterception(intc *Interception, options []InterceptOption) {
+	for _, option := range options {
+		option(intc)
+	}
+	interceptors = append(interceptors, intc)
+	sort.SliceStable(interceptors, func(i, j int) bool {
+		return interceptors[i].Priority > interceptors[j].Priority
 	})
 }
 
-func getInterceptors(when When, val reflect.Value) []*Interception {
-	result := []*Interception{}
+// getInterceptors returns the interceptors to call at the given time for the
+// action of the controller.
+func getInterceptors(when When, c *Controller) []*Interception {
+	var (
+		val    = reflect.ValueOf(c.AppController)
+		result = []*Interception{}
+	)
 	for _, intc := range interceptors {
-		if intc.When != when {
+		if intc.When != when || !intc.appliesTo(c) {
 			continue
 		}
 
@@ -171,6 +246,39 @@ func getInterceptors(when When, val reflect.Value) []*Interception {
 	return result
 }
 
+// appliesTo returns whether the action and HTTP method of the controller are
+// targeted by the interception.
+func (i *Interception) appliesTo(c *Controller) bool {
+	if len(i.methods) > 0 {
+		method := http.MethodGet
+		if c.Request != nil && c.Request.Request != nil {
+			method = c.Request.Method
+		}
+		if !containsFold(i.methods, method) {
+			return false
+		}
+	}
+	if len(i.actions) > 0 && !matchesAction(i.actions, c) {
+		return false
+	}
+	return !matchesAction(i.exceptActions, c)
+}
+
+// matchesAction returns whether the action of the controller matches one of
+// the patterns.
+func matchesAction(patterns []string, c *Controller) bool {
+	for _, pattern := range patterns {
+		name := c.MethodName
+		if strings.Contains(pattern, ".") {
+			name = c.Action
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // Find the value of the target, starting from val and including embedded types.
 // Also, convert between any difference in indirection.
 // If the target couldn't be found, the returned Value will have IsValid() == false
@@ -1,7 +1,9 @@
 package revel
 
 import (
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -60,7 +62,7 @@ func testInterceptorController(t *testing.T, appControllerPtr reflect.Value, met
 	for _, m := range methods {
 		InterceptMethod(m, BEFORE)
 	}
-	ints := getInterceptors(BEFORE, appControllerPtr)
+	ints := getInterceptors(BEFORE, &Controller{AppController: appControllerPtr.Interface()})
 
 	if len(ints) != 6 {
 		t.Fatalf("N: Expected 6 interceptors, got %d.", len(ints))
@@ -79,3 +81,59 @@ func testInterception(t *testing.T, intc *Interception, arg reflect.Value) {
 		t.Errorf("Failed (%s): Expected nil got %s", intc, val)
 	}
 }
+
+func TestInterceptorPriority(t *testing.T) {
+	defer func(saved []*Interception) { interceptors = saved }(interceptors)
+	interceptors = []*Interception{}
+
+	var calls []string
+	intercept := func(name string) InterceptorFunc {
+		return func(c *Controller) Result {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	InterceptFunc(intercept("transaction"), BEFORE, ALL_CONTROLLERS)
+	InterceptFunc(intercept("logging"), BEFORE, ALL_CONTROLLERS, InterceptPriority(-1))
+	InterceptFunc(intercept("auth"), BEFORE, ALL_CONTROLLERS, InterceptPriority(10))
+	InterceptFunc(intercept("locale"), BEFORE, ALL_CONTROLLERS)
+
+	c := &Controller{}
+	c.AppController = &InterceptController{c}
+	invokeInterceptors(BEFORE, c)
+
+	expected := []string{"auth", "transaction", "locale", "logging"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected the interceptors to run as %v, got %v", expected, calls)
+	}
+}
+
+func TestInterceptorTargeting(t *testing.T) {
+	defer func(saved []*Interception) { interceptors = saved }(interceptors)
+	interceptors = []*Interception{}
+
+	InterceptFunc(funcP, BEFORE, ALL_CONTROLLERS, OnlyActions("Save*", "App.Index"))
+	InterceptFunc(funcP, BEFORE, ALL_CONTROLLERS, ExceptActions("Show"))
+	InterceptFunc(funcP, BEFORE, ALL_CONTROLLERS, OnlyMethods("post", "PUT"))
+	InterceptFunc(funcP, BEFORE, ALL_CONTROLLERS, OnlyActions("Hotels.*"), ExceptActions("*.Index"))
+
+	for _, test := range []struct {
+		action, method string
+		expected       []bool
+	}{
+		{"App.Index", "GET", []bool{true, true, false, false}},
+		{"App.SaveUser", "POST", []bool{true, true, true, false}},
+		{"Hotels.Show", "GET", []bool{false, false, false, true}},
+		{"Hotels.Index", "PUT", []bool{false, true, true, false}},
+	} {
+		httpRequest, _ := http.NewRequest(test.method, "/", nil)
+		c := NewController(NewRequest(httpRequest), nil)
+		c.Action = test.action
+		c.MethodName = test.action[strings.Index(test.action, ".")+1:]
+		for i, intc := range interceptors {
+			if applies := intc.appliesTo(c); applies != test.expected[i] {
+				t.Errorf("Expected interceptor %d to apply to %s %s: %v", i, test.method, test.action, test.expected[i])
+			}
+		}
+	}
+}
@@ -1,26 +1,144 @@
 package revel
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"hash"
 	"io"
 )
 
-// Sign a given string with the app-configured secret key.
+// Sign a given string with the app-configured secret key, using HMAC-SHA256.
 // If no secret key is set, returns the empty string.
-// Return the signature in base64 (URLEncoding).
+// Return the signature in hex.
 func Sign(message string) string {
 	if len(secretKey) == 0 {
 		return ""
 	}
-	mac := hmac.New(sha1.New, secretKey)
-	io.WriteString(mac, message)
-	return hex.EncodeToString(mac.Sum(nil))
+	return sign(sha256.New, secretKey, message)
 }
 
 // Verify returns true if the given signature is correct for the given message.
 // e.g. it matches what we generate with Sign()
+//
+// Signatures made with one of the "app.secret.previous" keys are accepted as
+// well, so that the secret can be rotated without invalidating every cookie,
+// and so are the HMAC-SHA1 signatures of earlier versions of Revel, unless
+// "cookie.accept_sha1" is false. Cookies verified this way are signed again
+// with the current key when they are next sent.
+//
+// HMAC-SHA1 signatures will no longer be accepted in the next release, when
+// "cookie.accept_sha1" is removed.
 func Verify(message, sig string) bool {
-	return hmac.Equal([]byte(sig), []byte(Sign(message)))
+	return verify(sha256.New, message, sig) || verifySHA1(message, sig)
+}
+
+// verify returns true if the signature of the message, with the given hash,
+// is made with the current or one of the previous secret keys.
+func verify(h func() hash.Hash, message, sig string) bool {
+	if len(secretKey) == 0 {
+		return sig == ""
+	}
+	for _, key := range secretKeys() {
+		if hmac.Equal([]byte(sig), []byte(sign(h, key, message))) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySHA1 returns true if the signature is a HMAC-SHA1 one of earlier
+// versions of Revel, while they are accepted.
+func verifySHA1(message, sig string) bool {
+	return acceptSHA1 && len(secretKey) > 0 && verify(sha1.New, message, sig)
+}
+
+func sign(h func() hash.Hash, key []byte, message string) string {
+	mac := hmac.New(h, key)
+	io.WriteString(mac, message)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// secretKeys returns the current secret key, followed by the previous ones.
+func secretKeys() [][]byte {
+	return append([][]byte{secretKey}, previousSecretKeys...)
+}
+
+var errNoSecretKey = errors.New("revel: app.secret is not set")
+
+// Encrypt encrypts and authenticates the given string with AES-GCM, using a
+// key derived from the app-configured secret key.
+// Return the nonce and ciphertext in base64 (URLEncoding, without padding).
+func Encrypt(message string) (string, error) {
+	return encrypt(message, "")
+}
+
+// Decrypt returns the string encrypted with Encrypt, using either the current
+// or one of the previous secret keys. It returns an error if the ciphertext
+// is invalid or was tampered with.
+func Decrypt(ciphertext string) (string, error) {
+	return decrypt(ciphertext, "")
+}
+
+// encrypt encrypts the message as Encrypt, bound to the given purpose, so that
+// it may only be decrypted for the same purpose.
+func encrypt(message, purpose string) (string, error) {
+	if len(secretKey) == 0 {
+		return "", errNoSecretKey
+	}
+
+	aead, err := newCookieCipher(secretKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(message), []byte(purpose))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt returns the message encrypted with encrypt for the given purpose.
+func decrypt(ciphertext, purpose string) (string, error) {
+	if len(secretKey) == 0 {
+		return "", errNoSecretKey
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	for _, key := range secretKeys() {
+		aead, err := newCookieCipher(key)
+		if err != nil {
+			return "", err
+		}
+		if len(data) < aead.NonceSize() {
+			break
+		}
+		nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+		if message, err := aead.Open(nil, nonce, sealed, []byte(purpose)); err == nil {
+			return string(message), nil
+		}
+	}
+	return "", errors.New("revel: message authentication failed")
+}
+
+// newCookieCipher returns AES-256-GCM with a key derived from the given secret,
+// so that the same key is never used both to sign and to encrypt.
+func newCookieCipher(secret []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, secret)
+	io.WriteString(mac, "revel cookie encryption")
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
 }
@@ -0,0 +1,97 @@
+package revel
+
+import (
+	"crypto/sha1"
+	"testing"
+)
+
+func withSecrets(current string, previous []string, fn func()) {
+	oldKey, oldPrevious := secretKey, previousSecretKeys
+	defer func() { secretKey, previousSecretKeys = oldKey, oldPrevious }()
+
+	secretKey = []byte(current)
+	previousSecretKeys = nil
+	for _, secret := range previous {
+		previousSecretKeys = append(previousSecretKeys, []byte(secret))
+	}
+	fn()
+}
+
+func TestSignAndVerify(t *testing.T) {
+	var oldSig, sig string
+	withSecrets("old secret", nil, func() {
+		oldSig = Sign("message")
+	})
+	withSecrets("new secret", []string{"old secret"}, func() {
+		sig = Sign("message")
+		if len(sig) != 64 {
+			t.Errorf("Expected a HMAC-SHA256 signature, got %s", sig)
+		}
+		if !Verify("message", sig) {
+			t.Error("Expected the signature of the current key to be valid")
+		}
+		if !Verify("message", oldSig) {
+			t.Error("Expected the signature of a previous key to be valid")
+		}
+		if Verify("other message", sig) {
+			t.Error("Expected the signature of another message to be invalid")
+		}
+	})
+	withSecrets("new secret", nil, func() {
+		if Verify("message", oldSig) {
+			t.Error("Expected the signature of a forgotten key to be invalid")
+		}
+	})
+}
+
+func TestVerifySHA1(t *testing.T) {
+	withSecrets("secret", nil, func() {
+		sig := sign(sha1.New, secretKey, "message")
+		if !Verify("message", sig) {
+			t.Error("Expected a HMAC-SHA1 signature to be accepted by default")
+		}
+
+		defer func() { acceptSHA1 = true }()
+		acceptSHA1 = false
+		if Verify("message", sig) {
+			t.Error("Expected a HMAC-SHA1 signature to be rejected with cookie.accept_sha1 off")
+		}
+	})
+}
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	var oldCiphertext string
+	withSecrets("old secret", nil, func() {
+		oldCiphertext, _ = Encrypt("message")
+	})
+	withSecrets("new secret", []string{"old secret"}, func() {
+		ciphertext, err := Encrypt("message")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other, _ := Encrypt("message"); other == ciphertext {
+			t.Error("Expected a new nonce for every encryption")
+		}
+		for _, c := range []string{ciphertext, oldCiphertext} {
+			if message, err := Decrypt(c); err != nil || message != "message" {
+				t.Errorf("Expected to decrypt %s, got %q, %v", c, message, err)
+			}
+		}
+
+		// The last character may carry unused bits, so change one in the middle.
+		tampered := []byte(ciphertext)
+		if i := len(tampered) / 2; tampered[i] == 'A' {
+			tampered[i] = 'B'
+		} else {
+			tampered[i] = 'A'
+		}
+		if _, err := Decrypt(string(tampered)); err == nil {
+			t.Error("Expected a tampered ciphertext to be rejected")
+		}
+	})
+	withSecrets("", nil, func() {
+		if _, err := Encrypt("message"); err == nil {
+			t.Error("Expected encryption to require a secret")
+		}
+	})
+}
@@ -0,0 +1,220 @@
+// This module provides authentication and authorization for the application.
+//
+// Developers use this module by importing it, logging users in with
+// auth.Login(c, principal), and protecting controllers with the interceptors:
+//
+//   revel.InterceptFunc(auth.Authenticated, revel.BEFORE, &Hotels{})
+//   revel.InterceptFunc(auth.RequireRole("admin"), revel.BEFORE, &Admin{})
+//
+// A positive revel.InterceptPriority runs them before the other interceptors,
+// e.g. those opening database transactions, and revel.ExceptActions leaves
+// some actions public:
+//
+//   revel.InterceptFunc(auth.Authenticated, revel.BEFORE, &Hotels{},
+//     revel.InterceptPriority(10), revel.ExceptActions("Index", "Show"))
+//
+// or single actions with the filters:
+//
+//   revel.FilterAction(App.Delete).Add(auth.RoleFilter("admin"))
+//
+// The principal of a request is first restored from the session, then looked
+// up with the registered Authenticators (e.g. HTTP Basic, bearer tokens or
+// API keys), which makes them usable for APIs without sessions.
+// It is available to templates with {{currentUser .}}, and to the code given
+// the request context with auth.FromContext(c.Context()).
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/golib/revel"
+	"net/http"
+)
+
+const (
+	// The session key of the principal of a logged in user.
+	SESSION_KEY = "_AUTH"
+
+	// The key of the principal in Controller.Args and RenderArgs.
+	principalArg = "_principal"
+)
+
+// ErrInvalidCredentials may be returned by authenticators for credentials
+// that were given but are not valid.
+var ErrInvalidCredentials = errors.New("auth: invalid credentials")
+
+// A Principal is an authenticated user.
+type Principal struct {
+	Id    string
+	Name  string
+	Roles []string
+}
+
+// HasRole returns true if the principal has any of the given roles.
+func (p *Principal) HasRole(roles ...string) bool {
+	if p == nil {
+		return false
+	}
+	for _, role := range roles {
+		for _, r := range p.Roles {
+			if r == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// An Authenticator finds the principal of a request from its credentials.
+// It returns a nil Principal, and no error, if the request does not carry
+// the credentials it handles.
+type Authenticator interface {
+	Authenticate(c *revel.Controller) (*Principal, error)
+}
+
+// Authenticators are tried in order when there is no logged in user.
+var Authenticators []Authenticator
+
+func init() {
+	// Make the principal available to all templates.
+	revel.InterceptFunc(func(c *revel.Controller) revel.Result {
+		c.RenderArgs[principalArg] = Current(c)
+		return nil
+	}, revel.BEFORE, revel.ALL_CONTROLLERS)
+
+	revel.TemplateHelpers["currentUser"] = func(renderArgs map[string]interface{}) *Principal {
+		principal, _ := renderArgs[principalArg].(*Principal)
+		return principal
+	}
+}
+
+// Current returns the principal of the request, or nil if it is anonymous.
+func Current(c *revel.Controller) *Principal {
+	if principal, ok := c.Args[principalArg]; ok {
+		return principal.(*Principal)
+	}
+
+	var principal *Principal
+	if c.Session != nil {
+		var p Principal
+		if err := c.Session.Get(SESSION_KEY, &p); err == nil {
+			principal = &p
+		}
+	}
+	for _, authenticator := range Authenticators {
+		if principal != nil {
+			break
+		}
+		p, err := authenticator.Authenticate(c)
+		if err != nil && err != ErrInvalidCredentials {
+			revel.ERROR.Println("auth: authenticator failed:", err)
+		}
+		if err == nil {
+			principal = p
+		}
+	}
+
+	setPrincipal(c, principal)
+	return principal
+}
+
+// FromContext returns the principal of the request of the context, or nil if
+// it is anonymous or unknown yet.
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
+
+type principalContextKey struct{}
+
+// setPrincipal makes the principal the current one of the request.
+func setPrincipal(c *revel.Controller, principal *Principal) {
+	c.Args[principalArg] = principal
+	c.SetContext(context.WithValue(c.Context(), principalContextKey{}, principal))
+}
+
+// Login stores the principal in the session, after giving the session a new
+// id to prevent session fixation.
+func Login(c *revel.Controller, principal *Principal) error {
+	c.Session.Regenerate()
+	if err := c.Session.Set(SESSION_KEY, principal); err != nil {
+		return err
+	}
+	c.Session.SetUser(principal.Id)
+	setPrincipal(c, principal)
+	c.RenderArgs[principalArg] = principal
+	return nil
+}
+
+// Logout destroys the session of the user.
+func Logout(c *revel.Controller) {
+	c.Session.Destroy()
+	setPrincipal(c, nil)
+	c.RenderArgs[principalArg] = (*Principal)(nil)
+}
+
+// Authenticated is an interceptor rejecting anonymous requests, see
+// Unauthorized.
+func Authenticated(c *revel.Controller) revel.Result {
+	if Current(c) == nil {
+		return Unauthorized(c)
+	}
+	return nil
+}
+
+// RequireRole returns an interceptor rejecting requests whose principal has
+// none of the given roles.
+func RequireRole(roles ...string) revel.InterceptorFunc {
+	return func(c *revel.Controller) revel.Result {
+		principal := Current(c)
+		if principal == nil {
+			return Unauthorized(c)
+		}
+		if !principal.HasRole(roles...) {
+			return c.Forbidden("Access denied")
+		}
+		return nil
+	}
+}
+
+// AuthenticatedFilter is a Revel Filter rejecting anonymous requests, to be
+// added to actions or controllers with the FilterConfigurator.
+func AuthenticatedFilter(c *revel.Controller, fc []revel.Filter) {
+	if result := Authenticated(c); result != nil {
+		c.Result = result
+		return
+	}
+	fc[0](c, fc[1:])
+}
+
+// RoleFilter returns a Revel Filter rejecting requests whose principal has
+// none of the given roles, to be added to actions or controllers with the
+// FilterConfigurator.
+func RoleFilter(roles ...string) revel.Filter {
+	check := RequireRole(roles...)
+	return func(c *revel.Controller, fc []revel.Filter) {
+		if result := check(c); result != nil {
+			c.Result = result
+			return
+		}
+		fc[0](c, fc[1:])
+	}
+}
+
+// Unauthorized returns the result for anonymous requests: a redirect to
+// "auth.login_url" if it is set and the request is from a browser, or else a
+// 401, challenging for HTTP Basic credentials if it is accepted.
+func Unauthorized(c *revel.Controller) revel.Result {
+	if loginUrl, ok := revel.Config.String("auth.login_url"); ok && c.Request.Format == "html" {
+		c.Flash.Error("Please log in first")
+		return c.Redirect(loginUrl)
+	}
+
+	for _, authenticator := range Authenticators {
+		if basic, ok := authenticator.(BasicAuthenticator); ok {
+			c.Response.Out.Header().Set("WWW-Authenticate", `Basic realm="`+basic.Realm+`"`)
+			break
+		}
+	}
+	return c.Unauthorized("%s", http.StatusText(http.StatusUnauthorized))
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"github.com/golib/revel"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testUsers = map[string]*Principal{
+	"tom":   {Id: "1", Name: "tom", Roles: []string{"user"}},
+	"admin": {Id: "2", Name: "admin", Roles: []string{"user", "admin"}},
+}
+
+func verifyPassword(user, password string) (*Principal, error) {
+	if principal, ok := testUsers[user]; ok && password == "secret" {
+		return principal, nil
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// withAuthTest runs the test function with an empty configuration and the
+// given authenticators.
+func withAuthTest(t *testing.T, authenticators []Authenticator, fn func()) {
+	dir, err := ioutil.TempDir("", "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "app.conf"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(paths []string, config *revel.MergedConfig, saved []Authenticator) {
+		revel.ConfPaths, revel.Config, Authenticators = paths, config, saved
+	}(revel.ConfPaths, revel.Config, Authenticators)
+	revel.ConfPaths = []string{dir}
+	if revel.Config, err = revel.LoadConfig("app.conf"); err != nil {
+		t.Fatal(err)
+	}
+	Authenticators = authenticators
+	fn()
+}
+
+func newTestController(setup func(r *http.Request)) *revel.Controller {
+	httpRequest, _ := http.NewRequest("GET", "/hotels", nil)
+	if setup != nil {
+		setup(httpRequest)
+	}
+	c := revel.NewController(revel.NewRequest(httpRequest), revel.NewResponse(httptest.NewRecorder()))
+	c.Session = revel.Session{}
+	return c
+}
+
+func TestAuthenticatorsRejectBadCredentials(t *testing.T) {
+	basic := BasicAuthenticator{Realm: "hotels", Verify: verifyPassword}
+	bearer := BearerAuthenticator{Verify: func(token string) (*Principal, error) {
+		if token == "valid" {
+			return testUsers["tom"], nil
+		}
+		return nil, ErrInvalidCredentials
+	}}
+
+	withAuthTest(t, []Authenticator{basic, bearer}, func() {
+		c := newTestController(func(r *http.Request) { r.SetBasicAuth("tom", "wrong") })
+		if principal, err := basic.Authenticate(c); principal != nil || err != ErrInvalidCredentials {
+			t.Errorf("Expected bad Basic credentials to be rejected, got %v, %v", principal, err)
+		}
+		if principal := Current(c); principal != nil {
+			t.Errorf("Expected no principal for bad credentials, got %v", principal)
+		}
+
+		c = newTestController(func(r *http.Request) { r.Header.Set("Authorization", "Bearer forged") })
+		if principal := Current(c); principal != nil {
+			t.Errorf("Expected no principal for a bad token, got %v", principal)
+		}
+
+		c = newTestController(func(r *http.Request) { r.SetBasicAuth("tom", "secret") })
+		if principal := Current(c); principal != testUsers["tom"] {
+			t.Errorf("Expected tom for good credentials, got %v", principal)
+		}
+		if FromContext(c.Context()) != testUsers["tom"] {
+			t.Errorf("Expected tom on the request context")
+		}
+	})
+}
+
+func TestLoginRegeneratesSession(t *testing.T) {
+	withAuthTest(t, nil, func() {
+		c := newTestController(nil)
+		c.Session["cart"] = "3"
+		anonymousId := c.Session.Id()
+
+		if err := Login(c, testUsers["tom"]); err != nil {
+			t.Fatal(err)
+		}
+		if c.Session.Id() == anonymousId {
+			t.Errorf("Expected the session id to change on login")
+		}
+		if c.Session["cart"] != "3" || c.Session[revel.SESSION_USER_KEY] != "1" {
+			t.Errorf("Expected the session to be kept, with the user set, got %v", c.Session)
+		}
+
+		// The next request restores the principal from the session.
+		next := newTestController(nil)
+		next.Session = c.Session
+		if principal := Current(next); principal == nil || principal.Id != "1" {
+			t.Errorf("Expected tom to be restored from the session, got %v", principal)
+		}
+	})
+}
+
+func TestAccessInterceptors(t *testing.T) {
+	withAuthTest(t, []Authenticator{BasicAuthenticator{Realm: "hotels", Verify: verifyPassword}}, func() {
+		c := newTestController(nil)
+		if result := Authenticated(c); result == nil || c.Response.Status != http.StatusUnauthorized {
+			t.Errorf("Expected a 401 for an anonymous request, got %d", c.Response.Status)
+		}
+		if challenge := c.Response.Out.Header().Get("WWW-Authenticate"); challenge != `Basic realm="hotels"` {
+			t.Errorf("Expected a Basic challenge, got %q", challenge)
+		}
+
+		c = newTestController(func(r *http.Request) { r.SetBasicAuth("tom", "secret") })
+		if result := Authenticated(c); result != nil {
+			t.Errorf("Expected tom to be let through, got %v", result)
+		}
+		if result := RequireRole("admin")(c); result == nil || c.Response.Status != http.StatusForbidden {
+			t.Errorf("Expected a 403 for tom, got %d", c.Response.Status)
+		}
+
+		c = newTestController(func(r *http.Request) { r.SetBasicAuth("admin", "secret") })
+		if result := RequireRole("admin")(c); result != nil {
+			t.Errorf("Expected the admin to be let through, got %v", result)
+		}
+	})
+}
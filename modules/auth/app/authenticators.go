@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"github.com/golib/revel"
+	"strings"
+)
+
+// FormAuthenticator checks the user name and password posted by a login
+// form. It is meant to be used by the login action, e.g.
+//
+//   func (c App) Login() revel.Result {
+//     principal, err := FormLogin.Authenticate(c.Controller)
+//     if principal == nil || err != nil {
+//       c.Flash.Error("Login failed")
+//       return c.Redirect(App.Index)
+//     }
+//     auth.Login(c.Controller, principal)
+//     return c.Redirect(Hotels.Index)
+//   }
+type FormAuthenticator struct {
+	UserField     string // "username" if empty
+	PasswordField string // "password" if empty
+	Verify        func(user, password string) (*Principal, error)
+}
+
+func (a FormAuthenticator) Authenticate(c *revel.Controller) (*Principal, error) {
+	userField, passwordField := a.UserField, a.PasswordField
+	if userField == "" {
+		userField = "username"
+	}
+	if passwordField == "" {
+		passwordField = "password"
+	}
+
+	if c.Params.Form == nil {
+		return nil, nil
+	}
+	user := c.Params.Form.Get(userField)
+	if user == "" {
+		return nil, nil
+	}
+	return a.Verify(user, c.Params.Form.Get(passwordField))
+}
+
+// BasicAuthenticator checks HTTP Basic credentials. Unauthorized requests are
+// challenged for them when it is one of the Authenticators.
+type BasicAuthenticator struct {
+	Realm  string
+	Verify func(user, password string) (*Principal, error)
+}
+
+func (a BasicAuthenticator) Authenticate(c *revel.Controller) (*Principal, error) {
+	user, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+	return a.Verify(user, password)
+}
+
+// BearerAuthenticator checks the token of an "Authorization: Bearer" header.
+type BearerAuthenticator struct {
+	Verify func(token string) (*Principal, error)
+}
+
+func (a BearerAuthenticator) Authenticate(c *revel.Controller) (*Principal, error) {
+	authorization := c.Request.Header.Get("Authorization")
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return nil, nil
+	}
+	return a.Verify(strings.TrimSpace(authorization[7:]))
+}
+
+// APIKeyAuthenticator checks an API key sent in a header, or in a query
+// parameter.
+type APIKeyAuthenticator struct {
+	Header string // e.g. "X-API-Key"
+	Param  string // e.g. "api_key", not checked if empty
+	Verify func(key string) (*Principal, error)
+}
+
+func (a APIKeyAuthenticator) Authenticate(c *revel.Controller) (*Principal, error) {
+	var key string
+	if a.Header != "" {
+		key = c.Request.Header.Get(a.Header)
+	}
+	if key == "" && a.Param != "" && c.Params.Query != nil {
+		key = c.Params.Query.Get(a.Param)
+	}
+	if key == "" {
+		return nil, nil
+	}
+	return a.Verify(key)
+}
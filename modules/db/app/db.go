@@ -43,9 +43,9 @@ type Transactional struct {
 	Txn *sql.Tx
 }
 
-// Begin a transaction
+// Begin a transaction, which is rolled back if the request is cancelled.
 func (c *Transactional) Begin() revel.Result {
-	txn, err := Db.Begin()
+	txn, err := Db.BeginTx(c.Context(), nil)
 	if err != nil {
 		panic(err)
 	}
@@ -66,11 +66,12 @@ func (c *Transactional) Rollback() revel.Result {
 	return nil
 }
 
-// Commit the transaction.
+// Commit the transaction. It was already rolled back if the request was
+// cancelled.
 func (c *Transactional) Commit() revel.Result {
 	if c.Txn != nil {
 		if err := c.Txn.Commit(); err != nil {
-			if err != sql.ErrTxDone {
+			if err != sql.ErrTxDone && c.Context().Err() == nil {
 				panic(err)
 			}
 		}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"github.com/golib/revel"
+	"sort"
+	"strings"
+)
+
+// FilterChains shows the filters run for each action, in dev mode.
+type FilterChains struct {
+	*revel.Controller
+}
+
+// ActionFilters describes the filter chain of an action.
+type ActionFilters struct {
+	Action     string
+	Filters    []string
+	Overridden bool // Whether the chain differs from revel.Filters.
+}
+
+func (c FilterChains) Index() revel.Result {
+	if !revel.DevMode {
+		return c.NotFound("Filter chains are only shown in dev mode")
+	}
+
+	filters := filterNames(revel.Filters)
+	defaultChain := strings.Join(filters, ",")
+
+	// Describe each action of the routes, in order.
+	var (
+		actions []ActionFilters
+		seen    = make(map[string]bool)
+	)
+	for _, route := range revel.MainRouter.Routes {
+		if route.ControllerName == "" || route.MethodName == "" ||
+			route.ControllerName[0] == ':' || route.MethodName[0] == ':' {
+			continue
+		}
+		var action revel.Controller
+		if err := action.SetAction(route.ControllerName, route.MethodName); err != nil || seen[action.Action] {
+			continue
+		}
+		seen[action.Action] = true
+
+		chain := filterNames(revel.FilterChain(action.Action))
+		actions = append(actions, ActionFilters{
+			Action:     action.Action,
+			Filters:    chain,
+			Overridden: strings.Join(chain, ",") != defaultChain,
+		})
+	}
+	sort.Sort(byAction(actions))
+
+	return c.Render(filters, actions)
+}
+
+func filterNames(filters []revel.Filter) []string {
+	names := make([]string, len(filters))
+	for i, f := range filters {
+		names[i] = revel.FilterName(f)
+	}
+	return names
+}
+
+type byAction []ActionFilters
+
+func (a byAction) Len() int           { return len(a) }
+func (a byAction) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byAction) Less(i, j int) bool { return a[i].Action < a[j].Action }
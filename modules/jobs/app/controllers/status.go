@@ -4,7 +4,6 @@ import (
 	"github.com/golib/revel"
 	"github.com/golib/revel/modules/jobs/app/jobs"
 	"github.com/robfig/cron"
-	"strings"
 )
 
 type Jobs struct {
@@ -12,8 +11,8 @@ type Jobs struct {
 }
 
 func (c Jobs) Status() revel.Result {
-	if _, ok := c.Request.Header["X-Forwarded-For"]; ok || !strings.HasPrefix(c.Request.RemoteAddr, "127.0.0.1:") {
-		return c.Forbidden("%s is not local", c.Request.RemoteAddr)
+	if !c.Request.IsLocal() {
+		return c.Forbidden("%s is not local", c.Request.ClientIP())
 	}
 	entries := jobs.MainCron.Entries()
 	return c.Render(entries)
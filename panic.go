@@ -16,8 +16,18 @@ func PanicFilter(c *Controller, fc []Filter) {
 }
 
 // This function handles a panic in an action invocation.
-// It cleans up the stack trace, logs it, and displays an error page.
+// Errors registered with RegisterErrorStatus are rendered with their status.
+// Otherwise, it cleans up the stack trace, logs it, and displays an error page.
 func handleInvocationPanic(c *Controller, err interface{}) {
+	if c.PanicError == nil {
+		c.PanicError = panicError(err)
+	}
+	if result, ok := registeredErrorResult(c, c.PanicError); ok {
+		INFO.Println("Action", c.Action, "panicked with", c.PanicError)
+		c.Result = result
+		return
+	}
+
 	error := NewErrorFromPanic(err)
 	if error == nil {
 		ERROR.Print(err, "\n", string(debug.Stack()))
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"strings"
 )
 
 // Params provides a unified view of the request params.
@@ -13,6 +14,9 @@ import (
 // - Form values
 // - File uploads
 //
+// Request headers and cookies are kept apart from Values. They may only be
+// bound through struct fields tagged with `from:"header"` or `from:"cookie"`.
+//
 // Warning: param maps other than Values may be nil if there were none.
 type Params struct {
 	url.Values // A unified view of all the individual param maps below.
@@ -22,15 +26,26 @@ type Params struct {
 	Route url.Values // Parameters extracted from the route,  e.g. /customers/{id}
 
 	// Set by the ParamsFilter
-	Query url.Values // Parameters from the query string, e.g. /index?limit=10
-	Form  url.Values // Parameters from the request body.
+	Query  url.Values // Parameters from the query string, e.g. /index?limit=10
+	Form   url.Values // Parameters from the request body.
+	Header url.Values // Request headers, keyed by canonical header name.
+	Cookie url.Values // Request cookies, keyed by cookie name.
 
 	Files    map[string][]*multipart.FileHeader // Files uploaded in a multipart form
 	tmpFiles []*os.File                         // Temp files used during the request.
+
+	bindElements *int // Slice and map elements bound by the current Bind call.
 }
 
 func ParseParams(params *Params, req *Request) {
 	params.Query = req.URL.Query()
+	params.Header = url.Values(req.Header)
+	for _, cookie := range req.Cookies() {
+		if params.Cookie == nil {
+			params.Cookie = make(url.Values)
+		}
+		params.Cookie.Add(cookie.Name, cookie.Value)
+	}
 
 	// Parse the body depending on the content type.
 	switch req.ContentType {
@@ -71,6 +86,27 @@ func (p *Params) Bind(dest interface{}, name string) {
 	value.Set(Bind(p, name, value.Type()))
 }
 
+// Source returns the param map of the named source, one of "path" (or
+// "route"), "fixed", "query", "form", "header" or "cookie".
+// Returns nil for an unknown source.
+func (p *Params) Source(from string) url.Values {
+	switch strings.ToLower(from) {
+	case "path", "route":
+		return p.Route
+	case "fixed":
+		return p.Fixed
+	case "query":
+		return p.Query
+	case "form":
+		return p.Form
+	case "header":
+		return p.Header
+	case "cookie":
+		return p.Cookie
+	}
+	return nil
+}
+
 // calcValues returns a unified view of the component param maps.
 func (p *Params) calcValues() url.Values {
 	numParams := len(p.Query) + len(p.Fixed) + len(p.Route) + len(p.Form)
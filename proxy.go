@@ -0,0 +1,217 @@
+package revel
+
+import (
+	"net"
+	"strings"
+)
+
+// The networks of the proxies trusted to report the client of a request in
+// the Forwarded or X-Forwarded-* headers. Set with "http.trusted_proxies" in
+// app.conf, e.g. 10.0.0.0/8,127.0.0.1
+var trustedProxies []*net.IPNet
+
+func init() {
+	OnAppStart(func() {
+		trustedProxies = nil
+		for _, proxy := range splitConfigList(Config.StringDefault("http.trusted_proxies", "")) {
+			network, err := parseTrustedProxy(proxy)
+			if err != nil {
+				ERROR.Fatalln("http.trusted_proxies invalid:", err)
+			}
+			trustedProxies = append(trustedProxies, network)
+		}
+	})
+}
+
+// parseTrustedProxy parses a CIDR, or a single IP address.
+func parseTrustedProxy(proxy string) (*net.IPNet, error) {
+	if !strings.Contains(proxy, "/") {
+		if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+			proxy += "/32"
+		} else {
+			proxy += "/128"
+		}
+	}
+	_, network, err := net.ParseCIDR(proxy)
+	return network, err
+}
+
+func isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the IP address of the peer of the connection.
+func (r *Request) remoteIP() string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ClientIP returns the IP address of the client. The Forwarded header, or
+// else the X-Forwarded-For header, is followed back through the trusted
+// proxies, up to the first address that is not one of them.
+func (r *Request) ClientIP() string {
+	ip := r.remoteIP()
+	if !isTrustedProxy(ip) {
+		return ip
+	}
+
+	hops := r.forwardedHops()
+	for i := len(hops) - 1; i >= 0; i-- {
+		if net.ParseIP(hops[i]) == nil {
+			break
+		}
+		ip = hops[i]
+		if !isTrustedProxy(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// IsLocal returns whether the client is on the loopback interface. Requests
+// forwarded by a peer that is not a trusted proxy are not local, e.g. from a
+// proxy on 127.0.0.1 when "http.trusted_proxies" is not set.
+func (r *Request) IsLocal() bool {
+	if !isTrustedProxy(r.remoteIP()) {
+		for _, header := range []string{"Forwarded", "X-Forwarded-For", "X-Real-Ip"} {
+			if _, ok := r.Header[header]; ok {
+				return false
+			}
+		}
+	}
+	ip := net.ParseIP(r.ClientIP())
+	return ip != nil && ip.IsLoopback()
+}
+
+// Scheme returns "https" or "http", as seen by the client. It is taken from
+// the Forwarded or X-Forwarded-Proto header of a trusted proxy.
+func (r *Request) Scheme() string {
+	if scheme := r.forwardedValue("proto", "X-Forwarded-Proto"); scheme == "http" || scheme == "https" {
+		return scheme
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// RequestHost returns the host requested by the client. It is taken from the
+// Forwarded or X-Forwarded-Host header of a trusted proxy, or else is the Host
+// of the request.
+func (r *Request) RequestHost() string {
+	if host := r.forwardedValue("host", "X-Forwarded-Host"); host != "" {
+		return host
+	}
+	return r.Request.Host
+}
+
+// AbsoluteUrl returns the URL of the given path, e.g. "/hotels", for the
+// scheme and host of the request.
+func (r *Request) AbsoluteUrl(path string) string {
+	return r.Scheme() + "://" + r.RequestHost() + path
+}
+
+// isForwarded returns whether a trusted proxy set the scheme or the host of
+// the request.
+func (r *Request) isForwarded() bool {
+	return r.forwardedValue("proto", "X-Forwarded-Proto") != "" ||
+		r.forwardedValue("host", "X-Forwarded-Host") != ""
+}
+
+// forwardedHops returns the addresses of the Forwarded header, or else of the
+// X-Forwarded-For header, the closest to the server last.
+func (r *Request) forwardedHops() []string {
+	if elements := r.forwarded(); len(elements) > 0 {
+		hops := make([]string, len(elements))
+		for i, element := range elements {
+			hops[i] = forwardedNode(element["for"])
+		}
+		return hops
+	}
+	return splitConfigList(strings.Join(r.Header["X-Forwarded-For"], ","))
+}
+
+// forwardedValue returns the value of the key in the Forwarded header, or
+// else of the given X-Forwarded-* header. As in ClientIP, the values are
+// followed back from the peer through the trusted proxies, and the one set by
+// the proxy the furthest from the server is returned, so that the client may
+// not spoof it. It is empty unless the request comes from a trusted proxy.
+func (r *Request) forwardedValue(key, header string) string {
+	if !isTrustedProxy(r.remoteIP()) {
+		return ""
+	}
+
+	var values []string
+	if elements := r.forwarded(); len(elements) > 0 {
+		for _, element := range elements {
+			values = append(values, element[key])
+		}
+	} else {
+		values = splitConfigList(strings.Join(r.Header[header], ","))
+	}
+
+	// The value at len(values)-i was set by the proxy the hop at
+	// len(hops)-i connected to.
+	var (
+		hops  = r.forwardedHops()
+		value string
+	)
+	for i := 1; i <= len(values); i++ {
+		if v := values[len(values)-i]; v != "" {
+			value = v
+		}
+		if i > len(hops) || !isTrustedProxy(hops[len(hops)-i]) {
+			break
+		}
+	}
+	return value
+}
+
+// forwarded parses the elements of the Forwarded header (RFC 7239), e.g.
+//   Forwarded: for=192.0.2.60;proto=https, for="[2001:db8::1]:4711"
+func (r *Request) forwarded() []map[string]string {
+	var elements []map[string]string
+	for _, header := range r.Header["Forwarded"] {
+		for _, element := range strings.Split(header, ",") {
+			pairs := make(map[string]string)
+			for _, pair := range strings.Split(element, ";") {
+				if i := strings.Index(pair, "="); i > 0 {
+					key := strings.ToLower(strings.TrimSpace(pair[:i]))
+					pairs[key] = strings.Trim(strings.TrimSpace(pair[i+1:]), `"`)
+				}
+			}
+			elements = append(elements, pairs)
+		}
+	}
+	return elements
+}
+
+// forwardedNode returns the IP address of a "for" node, without its port.
+// Obfuscated and unknown nodes are empty.
+func forwardedNode(node string) string {
+	if strings.HasPrefix(node, "[") {
+		if i := strings.Index(node, "]"); i > 0 {
+			return node[1:i]
+		}
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		node = host
+	}
+	if net.ParseIP(node) == nil {
+		return ""
+	}
+	return node
+}
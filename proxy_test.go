@@ -0,0 +1,158 @@
+package revel
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// withTrustedProxies runs the test function with the given trusted proxies.
+func withTrustedProxies(t *testing.T, proxies []string, fn func()) {
+	defer func(saved []*net.IPNet) { trustedProxies = saved }(trustedProxies)
+	trustedProxies = nil
+	for _, proxy := range proxies {
+		network, err := parseTrustedProxy(proxy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trustedProxies = append(trustedProxies, network)
+	}
+	fn()
+}
+
+func proxiedRequest(remoteAddr string, headers map[string]string) *Request {
+	httpRequest, _ := http.NewRequest("GET", "http://internal:9000/hotels", nil)
+	httpRequest.RemoteAddr = remoteAddr
+	for key, value := range headers {
+		httpRequest.Header.Set(key, value)
+	}
+	return NewRequest(httpRequest)
+}
+
+func TestClientIP(t *testing.T) {
+	withTrustedProxies(t, []string{"10.0.0.0/8", "127.0.0.1"}, func() {
+		for _, test := range []struct {
+			remoteAddr string
+			headers    map[string]string
+			expected   string
+		}{
+			{"192.0.2.1:1234", nil, "192.0.2.1"},
+			// Untrusted peers may not forward.
+			{"192.0.2.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.7"}, "192.0.2.1"},
+			{"10.0.0.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.7"}, "198.51.100.7"},
+			// Spoofed hops before the first untrusted one are ignored.
+			{"127.0.0.1:1234", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+			{"10.0.0.1:1234", map[string]string{"X-Forwarded-For": "garbage"}, "10.0.0.1"},
+			{"10.0.0.1:1234", map[string]string{
+				"Forwarded":       `for="[2001:db8::1]:4711", for=10.0.0.2`,
+				"X-Forwarded-For": "198.51.100.7",
+			}, "2001:db8::1"},
+		} {
+			if ip := proxiedRequest(test.remoteAddr, test.headers).ClientIP(); ip != test.expected {
+				t.Errorf("Expected %s for %s %v, got %s", test.expected, test.remoteAddr, test.headers, ip)
+			}
+		}
+	})
+}
+
+func TestSchemeAndHost(t *testing.T) {
+	withTrustedProxies(t, []string{"10.0.0.0/8"}, func() {
+		headers := map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "www.example.com"}
+
+		req := proxiedRequest("10.0.0.1:1234", headers)
+		if req.Scheme() != "https" || req.RequestHost() != "www.example.com" {
+			t.Errorf("Expected the forwarded scheme and host, got %s %s", req.Scheme(), req.RequestHost())
+		}
+		if url := req.AbsoluteUrl("/hotels"); url != "https://www.example.com/hotels" {
+			t.Errorf("Unexpected absolute URL %s", url)
+		}
+
+		req = proxiedRequest("10.0.0.1:1234", map[string]string{"Forwarded": "for=192.0.2.1;proto=https;host=example.org"})
+		if req.Scheme() != "https" || req.RequestHost() != "example.org" {
+			t.Errorf("Expected the Forwarded scheme and host, got %s %s", req.Scheme(), req.RequestHost())
+		}
+
+		req = proxiedRequest("192.0.2.1:1234", headers)
+		if req.Scheme() != "http" || req.RequestHost() != "internal:9000" {
+			t.Errorf("Expected the headers of an untrusted peer to be ignored, got %s %s", req.Scheme(), req.RequestHost())
+		}
+		req.TLS = &tls.ConnectionState{}
+		if req.Scheme() != "https" {
+			t.Errorf("Expected https for a TLS connection")
+		}
+	})
+}
+
+func TestForwardedValueSpoofing(t *testing.T) {
+	withTrustedProxies(t, []string{"10.0.0.0/8"}, func() {
+		// The client sends its own headers, which the proxy appends to.
+		req := proxiedRequest("10.0.0.1:1234", map[string]string{
+			"X-Forwarded-For":   "198.51.100.7",
+			"X-Forwarded-Proto": "http, https",
+			"X-Forwarded-Host":  "evil.com, www.example.com",
+		})
+		if req.Scheme() != "https" || req.RequestHost() != "www.example.com" {
+			t.Errorf("Expected the values of the proxy, got %s %s", req.Scheme(), req.RequestHost())
+		}
+
+		req = proxiedRequest("10.0.0.1:1234", map[string]string{
+			"Forwarded": "for=1.2.3.4;host=evil.com, for=198.51.100.7;host=www.example.com;proto=https, for=10.0.0.2;host=internal",
+		})
+		if req.Scheme() != "https" || req.RequestHost() != "www.example.com" {
+			t.Errorf("Expected the Forwarded values of the outermost trusted proxy, got %s %s", req.Scheme(), req.RequestHost())
+		}
+	})
+}
+
+func TestIsLocal(t *testing.T) {
+	forwarded := map[string]string{"X-Forwarded-For": "198.51.100.7"}
+	withTrustedProxies(t, nil, func() {
+		if !proxiedRequest("127.0.0.1:1234", nil).IsLocal() {
+			t.Errorf("Expected a loopback peer to be local")
+		}
+		// A proxy on the loopback interface, which is not trusted.
+		if proxiedRequest("127.0.0.1:1234", forwarded).IsLocal() {
+			t.Errorf("Expected a request forwarded by an untrusted proxy not to be local")
+		}
+		if proxiedRequest("127.0.0.1:1234", map[string]string{"X-Real-Ip": "198.51.100.7"}).IsLocal() {
+			t.Errorf("Expected a request with X-Real-Ip not to be local")
+		}
+		if proxiedRequest("192.0.2.1:1234", nil).IsLocal() {
+			t.Errorf("Expected a remote peer not to be local")
+		}
+	})
+	withTrustedProxies(t, []string{"127.0.0.1"}, func() {
+		if proxiedRequest("127.0.0.1:1234", forwarded).IsLocal() {
+			t.Errorf("Expected a remote client behind a trusted proxy not to be local")
+		}
+		if !proxiedRequest("127.0.0.1:1234", map[string]string{"X-Forwarded-For": "127.0.0.1"}).IsLocal() {
+			t.Errorf("Expected a local client behind a trusted proxy to be local")
+		}
+	})
+}
+
+func TestRedirectBehindProxy(t *testing.T) {
+	withTrustedProxies(t, []string{"10.0.0.0/8"}, func() {
+		headers := map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "www.example.com"}
+		for _, test := range []struct {
+			remoteAddr string
+			headers    map[string]string
+			expected   string
+		}{
+			{"10.0.0.1:1234", headers, "https://www.example.com/hotels"},
+			// The Host header of the client is not used.
+			{"10.0.0.1:1234", nil, "/hotels"},
+			{"192.0.2.1:1234", headers, "/hotels"},
+		} {
+			recorder := httptest.NewRecorder()
+			req := proxiedRequest(test.remoteAddr, test.headers)
+			req.Request.Host = "evil.com"
+			(&RedirectToUrlResult{url: "/hotels"}).Apply(req, NewResponse(recorder))
+			if location := recorder.Header().Get("Location"); location != test.expected {
+				t.Errorf("Expected %s for %s %v, got %s", test.expected, test.remoteAddr, test.headers, location)
+			}
+		}
+	})
+}
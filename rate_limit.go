@@ -0,0 +1,348 @@
+package revel
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The algorithms of a RateLimit.
+type RateLimitAlgorithm int
+
+const (
+	// SLIDING_WINDOW allows Limit requests in any Window, estimating the count
+	// of the sliding window from the counts of the current and previous fixed
+	// windows.
+	SLIDING_WINDOW RateLimitAlgorithm = iota
+
+	// TOKEN_BUCKET allows bursts of up to Limit requests, refilling the bucket
+	// at a rate of Limit tokens per Window.
+	TOKEN_BUCKET
+)
+
+// A RateLimitKey returns the key whose requests are counted together.
+type RateLimitKey func(c *Controller) string
+
+// A RateLimit describes how many requests are allowed by RateLimitFilter.
+type RateLimit struct {
+	Limit     int           // The number of requests allowed per Window.
+	Window    time.Duration // The period of the limit.
+	Algorithm RateLimitAlgorithm
+
+	// Key selects what is limited. It defaults to RateLimitByIP.
+	Key RateLimitKey
+
+	// Name separates the counters of different limits. Requests to different
+	// actions are counted separately if it is empty.
+	Name string
+}
+
+// RateLimitByIP counts the requests of each client IP address.
+func RateLimitByIP(c *Controller) string {
+	return "ip:" + c.Request.ClientIP()
+}
+
+// RateLimitBySession counts the requests of each session. It must run after
+// the SessionFilter.
+func RateLimitBySession(c *Controller) string {
+	return "session:" + c.Session.Id()
+}
+
+// RateLimitByUser counts the requests of each user set with Session.SetUser,
+// and those of anonymous users by IP address.
+func RateLimitByUser(c *Controller) string {
+	if user, ok := c.Session[SESSION_USER_KEY]; ok && user != "" {
+		return "user:" + user
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitFilter returns a Revel Filter throttling requests beyond the given
+// limit with a 429 Too Many Requests. It is meant to be added to actions or
+// controllers with the FilterConfigurator, e.g.
+//
+//   revel.FilterAction(App.Login).
+//     Add(revel.RateLimitFilter(revel.RateLimit{Limit: 5, Window: time.Minute}))
+//
+// The counters are kept in the store selected with "ratelimit.store" in
+// app.conf: "memory" (the default) keeps them in this process, and the cache
+// package registers "cache", which shares them between servers.
+func RateLimitFilter(limit RateLimit) Filter {
+	if limit.Limit <= 0 || limit.Window <= 0 {
+		panic("revel: a RateLimit needs a positive Limit and Window")
+	}
+	if limit.Key == nil {
+		limit.Key = RateLimitByIP
+	}
+
+	return func(c *Controller, fc []Filter) {
+		now := time.Now()
+		allowed, remaining, reset := limit.take(currentRateLimitStore(), limit.counterKey(c), now)
+
+		header := c.Response.Out.Header()
+		header.Set("X-RateLimit-Limit", strconv.Itoa(limit.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(reset).Unix(), 10))
+		if !allowed {
+			header.Set("Retry-After", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+			c.Response.Status = http.StatusTooManyRequests
+			c.Result = c.RenderError(&Error{
+				Title:       "Too Many Requests",
+				Description: "Rate limit exceeded, retry later",
+			})
+			return
+		}
+
+		fc[0](c, fc[1:])
+	}
+}
+
+func (limit RateLimit) counterKey(c *Controller) string {
+	name := limit.Name
+	if name == "" {
+		name = c.Action
+	}
+	return "revel.ratelimit:" + name + ":" + limit.Key(c)
+}
+
+// take counts a request, and returns whether it is allowed, the number of
+// requests remaining, and the time until the limit resets (or, for a request
+// that is not allowed, until it may be retried).
+//
+// Errors of the store are logged, and let the request through.
+func (limit RateLimit) take(store RateLimitStore, key string, now time.Time) (bool, int, time.Duration) {
+	if limit.Algorithm == TOKEN_BUCKET {
+		return limit.takeToken(store, key, now)
+	}
+	return limit.takeSlidingWindow(store, key, now)
+}
+
+func (limit RateLimit) takeSlidingWindow(store RateLimitStore, key string, now time.Time) (bool, int, time.Duration) {
+	var (
+		window  = int64(limit.Window)
+		current = now.UnixNano() / window
+		elapsed = now.UnixNano() - current*window
+		reset   = time.Duration(window - elapsed)
+	)
+
+	// Weight the count of the previous window by how much of it overlaps the
+	// sliding window.
+	var previous, count uint64
+	currentKey := key + ":" + strconv.FormatInt(current, 10)
+	store.Get(key+":"+strconv.FormatInt(current-1, 10), &previous)
+	store.Get(currentKey, &count)
+	weighted := float64(previous) * float64(window-elapsed) / float64(window)
+
+	// Only the allowed requests are counted, so that a client retrying too
+	// early is let through again at the configured rate. Concurrent requests
+	// may read the same count, letting a few more through.
+	estimate := weighted + float64(count) + 1
+	if estimate > float64(limit.Limit) {
+		return false, 0, reset
+	}
+
+	// Create the counter of the current window, which is kept for the next
+	// one, and count the request.
+	store.Add(currentKey, uint64(0), 2*limit.Window)
+	newCount, err := store.Increment(currentKey, 1)
+	if err != nil {
+		ERROR.Println("Failed to count the request for the rate limit:", err)
+		return true, limit.Limit, reset
+	}
+
+	remaining := limit.Limit - int(math.Ceil(weighted+float64(newCount)))
+	if remaining < 0 {
+		remaining = 0
+	}
+	return true, remaining, reset
+}
+
+// The state of a token bucket kept in the RateLimitStore.
+type tokenBucket struct {
+	Tokens  float64
+	Updated int64 // UnixNano
+}
+
+// tokenBucketMutexes serialize the updates of a token bucket in this process,
+// each guarding the keys hashed to it, so that the requests for other keys do
+// not wait for the round trips to the store. The updates of several servers
+// sharing a cache may race, letting a few more requests through.
+var tokenBucketMutexes [64]sync.Mutex
+
+// tokenBucketMutex returns the mutex guarding the token bucket of the key.
+func tokenBucketMutex(key string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &tokenBucketMutexes[h.Sum32()%uint32(len(tokenBucketMutexes))]
+}
+
+func (limit RateLimit) takeToken(store RateLimitStore, key string, now time.Time) (bool, int, time.Duration) {
+	mutex := tokenBucketMutex(key)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var (
+		capacity = float64(limit.Limit)
+		rate     = capacity / float64(limit.Window) // tokens per nanosecond
+		bucket   tokenBucket
+	)
+	if err := store.Get(key, &bucket); err != nil {
+		bucket = tokenBucket{capacity, now.UnixNano()}
+	}
+
+	bucket.Tokens = math.Min(capacity, bucket.Tokens+float64(now.UnixNano()-bucket.Updated)*rate)
+	bucket.Updated = now.UnixNano()
+	allowed := bucket.Tokens >= 1
+	if allowed {
+		bucket.Tokens--
+	}
+	if err := store.Set(key, bucket, limit.Window); err != nil {
+		ERROR.Println("Failed to store the rate limit:", err)
+	}
+
+	reset := time.Duration((capacity - bucket.Tokens) / rate)
+	if !allowed {
+		reset = time.Duration((1 - bucket.Tokens) / rate)
+	}
+	return allowed, int(bucket.Tokens), reset
+}
+
+// A RateLimitStore keeps the counters of the RateLimitFilter. It is the part
+// of a cache.Cache that is needed, and behaves the same way: Get, Increment
+// and Add return an error for missing keys, or an existing key in the case
+// of Add.
+type RateLimitStore interface {
+	Get(key string, ptrValue interface{}) error
+	Set(key string, value interface{}, expires time.Duration) error
+	Add(key string, value interface{}, expires time.Duration) error
+	Increment(key string, n uint64) (newValue uint64, err error)
+}
+
+var (
+	rateLimitStores = map[string]RateLimitStore{
+		"memory": NewMemoryRateLimitStore(),
+	}
+
+	rateLimitStoreName = "memory"
+)
+
+func init() {
+	OnAppStart(func() {
+		rateLimitStoreName = Config.StringDefault("ratelimit.store", "memory")
+	})
+}
+
+// RegisterRateLimitStore makes a RateLimitStore available under the given
+// name for use in "ratelimit.store".
+func RegisterRateLimitStore(name string, store RateLimitStore) {
+	rateLimitStores[name] = store
+}
+
+// currentRateLimitStore returns the store selected in the configuration.
+func currentRateLimitStore() RateLimitStore {
+	if store, ok := rateLimitStores[rateLimitStoreName]; ok {
+		return store
+	}
+	WARN.Printf("Unknown ratelimit.store %s, using memory", rateLimitStoreName)
+	return rateLimitStores["memory"]
+}
+
+var (
+	errRateLimitMiss   = errors.New("revel: rate limit key not found")
+	errRateLimitExists = errors.New("revel: rate limit key already exists")
+)
+
+// MemoryRateLimitStore keeps the counters in memory, for a single server.
+type MemoryRateLimitStore struct {
+	mutex   sync.Mutex
+	entries map[string]rateLimitEntry
+	swept   time.Time
+}
+
+type rateLimitEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		entries: make(map[string]rateLimitEntry),
+		swept:   time.Now(),
+	}
+}
+
+func (s *MemoryRateLimitStore) Get(key string, ptrValue interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.get(key)
+	if !ok {
+		return errRateLimitMiss
+	}
+	reflect.ValueOf(ptrValue).Elem().Set(reflect.ValueOf(entry.value))
+	return nil
+}
+
+func (s *MemoryRateLimitStore) Set(key string, value interface{}, expires time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.set(key, value, expires)
+	return nil
+}
+
+func (s *MemoryRateLimitStore) Add(key string, value interface{}, expires time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.get(key); ok {
+		return errRateLimitExists
+	}
+	s.set(key, value, expires)
+	return nil
+}
+
+func (s *MemoryRateLimitStore) Increment(key string, n uint64) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.get(key)
+	if !ok {
+		return 0, errRateLimitMiss
+	}
+	value, ok := entry.value.(uint64)
+	if !ok {
+		return 0, errors.New("revel: rate limit value is not a counter")
+	}
+	entry.value = value + n
+	s.entries[key] = entry
+	return value + n, nil
+}
+
+// get returns the entry of the key, unless it is missing or expired.
+func (s *MemoryRateLimitStore) get(key string) (rateLimitEntry, bool) {
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return rateLimitEntry{}, false
+	}
+	return entry, true
+}
+
+// set stores the entry, removing the expired ones once a minute.
+func (s *MemoryRateLimitStore) set(key string, value interface{}, expires time.Duration) {
+	now := time.Now()
+	if now.Sub(s.swept) > time.Minute {
+		for k, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.swept = now
+	}
+	s.entries[key] = rateLimitEntry{value, now.Add(expires)}
+}
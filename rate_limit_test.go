@@ -0,0 +1,159 @@
+package revel
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// rateLimitTester runs the filter for a request from the given address, and
+// reports whether the action was reached.
+func rateLimitTester(filter Filter, remoteAddr string) (*Controller, bool) {
+	httpRequest, _ := http.NewRequest("POST", "http://example.com/login", nil)
+	httpRequest.RemoteAddr = remoteAddr
+	c := NewController(NewRequest(httpRequest), NewResponse(httptest.NewRecorder()))
+	c.Action = "App.Login"
+	c.Session = make(Session)
+
+	invoked := false
+	filter(c, []Filter{func(c *Controller, _ []Filter) {
+		invoked = true
+	}})
+	return c, invoked
+}
+
+func TestRateLimitFilter(t *testing.T) {
+	defer func(saved RateLimitStore) { rateLimitStores["memory"] = saved }(rateLimitStores["memory"])
+	rateLimitStores["memory"] = NewMemoryRateLimitStore()
+
+	for _, algorithm := range []RateLimitAlgorithm{SLIDING_WINDOW, TOKEN_BUCKET} {
+		filter := RateLimitFilter(RateLimit{
+			Limit:     3,
+			Window:    time.Hour,
+			Algorithm: algorithm,
+			Name:      fmt.Sprint("test", algorithm),
+		})
+
+		for i := 0; i < 3; i++ {
+			if _, invoked := rateLimitTester(filter, "10.0.0.1:1234"); !invoked {
+				t.Fatalf("Algorithm %d: expected request %d to be allowed", algorithm, i+1)
+			}
+		}
+
+		c, invoked := rateLimitTester(filter, "10.0.0.1:5678")
+		if invoked || c.Response.Status != http.StatusTooManyRequests {
+			t.Fatalf("Algorithm %d: expected the 4th request to be limited", algorithm)
+		}
+		header := c.Response.Out.Header()
+		if header.Get("X-RateLimit-Limit") != "3" || header.Get("X-RateLimit-Remaining") != "0" ||
+			header.Get("Retry-After") == "" || header.Get("X-RateLimit-Reset") == "" {
+			t.Errorf("Algorithm %d: unexpected headers %v", algorithm, header)
+		}
+
+		if _, invoked = rateLimitTester(filter, "10.0.0.2:1234"); !invoked {
+			t.Errorf("Algorithm %d: expected another address to be allowed", algorithm)
+		}
+	}
+}
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	var (
+		store = NewMemoryRateLimitStore()
+		limit = RateLimit{Limit: 10, Window: time.Minute}
+		start = time.Unix(0, 0).Add(1000 * time.Minute)
+	)
+
+	// Use up the limit at the end of a window.
+	for i := 0; i < 10; i++ {
+		if allowed, _, _ := limit.take(store, "k", start.Add(50*time.Second)); !allowed {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+	}
+
+	// Half way through the next window, half of the previous count remains.
+	for i := 0; i < 5; i++ {
+		if allowed, _, _ := limit.take(store, "k", start.Add(90*time.Second)); !allowed {
+			t.Fatalf("Expected request %d of the next window to be allowed", i+1)
+		}
+	}
+	if allowed, remaining, reset := limit.take(store, "k", start.Add(90*time.Second)); allowed ||
+		remaining != 0 || reset != 30*time.Second {
+		t.Errorf("Expected the request to be limited for 30s, got %v %v %v", allowed, remaining, reset)
+	}
+
+	// Rejected requests are not counted: a client hammering the server is let
+	// through again as the window slides.
+	for i := 0; i < 100; i++ {
+		limit.take(store, "k", start.Add(90*time.Second))
+	}
+	if allowed, _, _ := limit.take(store, "k", start.Add(130*time.Second)); !allowed {
+		t.Errorf("Expected a request to be allowed once the window slid")
+	}
+}
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	var (
+		store = NewMemoryRateLimitStore()
+		limit = RateLimit{Limit: 2, Window: time.Minute, Algorithm: TOKEN_BUCKET}
+		start = time.Now()
+	)
+
+	limit.take(store, "k", start)
+	limit.take(store, "k", start)
+	if allowed, _, reset := limit.take(store, "k", start); allowed || reset != 30*time.Second {
+		t.Errorf("Expected an empty bucket to refill a token in 30s, got %v %v", allowed, reset)
+	}
+	if allowed, remaining, _ := limit.take(store, "k", start.Add(30*time.Second)); !allowed || remaining != 0 {
+		t.Errorf("Expected a refilled token to be taken, got %v %v", allowed, remaining)
+	}
+}
+
+// blockingRateLimitStore blocks the reads of a key until it is released.
+type blockingRateLimitStore struct {
+	*MemoryRateLimitStore
+	key     string
+	release chan struct{}
+}
+
+func (s blockingRateLimitStore) Get(key string, ptrValue interface{}) error {
+	if key == s.key {
+		<-s.release
+	}
+	return s.MemoryRateLimitStore.Get(key, ptrValue)
+}
+
+func TestRateLimitTokenBucketLocking(t *testing.T) {
+	var (
+		store = blockingRateLimitStore{NewMemoryRateLimitStore(), "slow", make(chan struct{})}
+		limit = RateLimit{Limit: 2, Window: time.Minute, Algorithm: TOKEN_BUCKET}
+		other = "fast"
+	)
+	for i := 0; tokenBucketMutex(other) == tokenBucketMutex(store.key); i++ {
+		other = fmt.Sprintf("fast%d", i)
+	}
+
+	slow := make(chan bool)
+	go func() {
+		allowed, _, _ := limit.take(store, store.key, time.Now())
+		slow <- allowed
+	}()
+
+	// The bucket of another key is not held up by the slow one.
+	fast := make(chan bool)
+	go func() {
+		allowed, _, _ := limit.take(store, other, time.Now())
+		fast <- allowed
+	}()
+	select {
+	case <-fast:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the token of another key to be taken without waiting")
+	}
+
+	close(store.release)
+	if !<-slow {
+		t.Errorf("Expected the token of the slow key to be taken")
+	}
+}
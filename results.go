@@ -434,7 +434,14 @@ type RedirectToUrlResult struct {
 }
 
 func (r *RedirectToUrlResult) Apply(req *Request, resp *Response) {
-	resp.Out.Header().Set("Location", r.url)
+	url := r.url
+	if strings.HasPrefix(url, "/") && !strings.HasPrefix(url, "//") && req.isForwarded() {
+		// Redirect to the scheme and host seen by the client, e.g. behind a
+		// proxy terminating TLS. Otherwise the Host header is not trusted,
+		// and the URL is kept relative.
+		url = req.AbsoluteUrl(url)
+	}
+	resp.Out.Header().Set("Location", url)
 	resp.WriteHeader(http.StatusFound, "")
 }
 
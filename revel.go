@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
@@ -71,6 +72,12 @@ var (
 	// Cookie flags
 	CookieHttpOnly bool
 	CookieSecure   bool
+	CookieSameSite http.SameSite // e.g. http.SameSiteLaxMode, unset by default
+	CookieDomain   string        // e.g. ".example.com", unset by default
+	CookiePath     string        // e.g. "/app", "/" by default
+
+	// If true, the session and flash cookies are encrypted (requires app.secret).
+	CookieEncrypt bool
 
 	//Logger colors
 	colors = map[string]gocolorize.Colorize{
@@ -91,8 +98,10 @@ var (
 	Initialized bool
 
 	// Private
-	secretKey []byte // Key used to sign cookies. An empty key disables signing.
-	packaged  bool   // If true, this is running from a pre-built package.
+	secretKey          []byte   // Key used to sign cookies. An empty key disables signing.
+	previousSecretKeys [][]byte // Keys that were used before, still accepted by Verify.
+	acceptSHA1         = true   // If true, Verify accepts the HMAC-SHA1 signatures of earlier versions.
+	packaged           bool     // If true, this is running from a pre-built package.
 )
 
 func init() {
@@ -192,9 +201,46 @@ func Init(mode, importPath, srcPath string) {
 	CookiePrefix = Config.StringDefault("cookie.prefix", "REVEL")
 	CookieHttpOnly = Config.BoolDefault("cookie.httponly", false)
 	CookieSecure = Config.BoolDefault("cookie.secure", false)
+	CookieDomain = Config.StringDefault("cookie.domain", "")
+	CookiePath = Config.StringDefault("cookie.path", "/")
+	switch sameSite := strings.ToLower(Config.StringDefault("cookie.samesite", "")); sameSite {
+	case "":
+		CookieSameSite = 0
+	case "lax":
+		CookieSameSite = http.SameSiteLaxMode
+	case "strict":
+		CookieSameSite = http.SameSiteStrictMode
+	case "none":
+		// Browsers reject SameSite=None cookies without Secure.
+		CookieSameSite = http.SameSiteNoneMode
+		CookieSecure = true
+	default:
+		log.Fatalln("cookie.samesite must be lax, strict or none, not", sameSite)
+	}
+
+	// The __Host- prefix makes browsers only accept the cookies when they are
+	// secure, for the whole host, and not shared with other domains.
+	if Config.BoolDefault("cookie.host_prefix", false) {
+		if CookieDomain != "" || CookiePath != "/" {
+			log.Fatalln("cookie.host_prefix can not be used with cookie.domain or cookie.path.")
+		}
+		CookiePrefix = "__Host-" + CookiePrefix
+		CookieSecure = true
+	}
+	CookieEncrypt = Config.BoolDefault("cookie.encrypt", false)
 	if secretStr := Config.StringDefault("app.secret", ""); secretStr != "" {
 		secretKey = []byte(secretStr)
 	}
+	previousSecretKeys = nil
+	for _, secretStr := range strings.Split(Config.StringDefault("app.secret.previous", ""), ",") {
+		if secretStr = strings.TrimSpace(secretStr); secretStr != "" {
+			previousSecretKeys = append(previousSecretKeys, []byte(secretStr))
+		}
+	}
+	acceptSHA1 = Config.BoolDefault("cookie.accept_sha1", true)
+	if CookieEncrypt && len(secretKey) == 0 {
+		log.Fatalln("cookie.encrypt requires app.secret to be set.")
+	}
 
 	// Configure logging.
 	TRACE = getLogger("trace")
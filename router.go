@@ -348,6 +348,12 @@ func (a *ActionDefinition) String() string {
 	return a.Url
 }
 
+// AbsoluteUrl returns the URL of the action for the scheme and host of the
+// given request.
+func (a *ActionDefinition) AbsoluteUrl(req *Request) string {
+	return req.AbsoluteUrl(a.Url)
+}
+
 func (router *Router) Reverse(action string, argValues map[string]string) *ActionDefinition {
 	actionSplit := strings.Split(action, ".")
 	if len(actionSplit) != 2 {
@@ -0,0 +1,134 @@
+package revel
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// The render arg holding the nonce of the Content-Security-Policy, to be
+	// set on inline scripts and styles: <script nonce="{{.cspNonce}}">
+	CSPNonceRenderArg = "cspNonce"
+
+	// The placeholder replaced with the nonce in "headers.csp".
+	cspNoncePlaceholder = "{nonce}"
+
+	// The largest CSP violation report that is read.
+	maxCSPReportSize = 64 << 10
+)
+
+// The headers set by the SecureHeadersFilter, configured in app.conf with
+// headers.*. Empty values are not sent.
+var secureHeaders struct {
+	hsts               string // Strict-Transport-Security, only sent over HTTPS.
+	contentTypeOptions string
+	frameOptions       string
+	referrerPolicy     string
+	permissionsPolicy  string
+	csp                string
+	cspReportOnly      bool
+	cspReportUri       string
+}
+
+func init() {
+	OnAppStart(func() {
+		maxAge, err := time.ParseDuration(Config.StringDefault("headers.hsts", "0"))
+		if err != nil {
+			ERROR.Fatalln("headers.hsts invalid:", err)
+		}
+		secureHeaders.hsts = ""
+		if maxAge > 0 {
+			secureHeaders.hsts = "max-age=" + strconv.FormatInt(int64(maxAge.Seconds()), 10)
+			if Config.BoolDefault("headers.hsts.include_subdomains", false) {
+				secureHeaders.hsts += "; includeSubDomains"
+			}
+			if Config.BoolDefault("headers.hsts.preload", false) {
+				secureHeaders.hsts += "; preload"
+			}
+		}
+
+		secureHeaders.contentTypeOptions = Config.StringDefault("headers.content_type_options", "nosniff")
+		secureHeaders.frameOptions = Config.StringDefault("headers.frame_options", "SAMEORIGIN")
+		secureHeaders.referrerPolicy = Config.StringDefault("headers.referrer_policy", "strict-origin-when-cross-origin")
+		secureHeaders.permissionsPolicy = Config.StringDefault("headers.permissions_policy", "")
+		secureHeaders.csp = Config.StringDefault("headers.csp", "")
+		secureHeaders.cspReportOnly = Config.BoolDefault("headers.csp.report_only", false)
+		secureHeaders.cspReportUri = Config.StringDefault("headers.csp.report_uri", "")
+		if secureHeaders.csp != "" && secureHeaders.cspReportUri != "" {
+			secureHeaders.csp += "; report-uri " + secureHeaders.cspReportUri
+		}
+	})
+}
+
+// SecureHeadersFilter is a Revel Filter that adds the security headers
+// configured with headers.* in app.conf: Strict-Transport-Security (over
+// HTTPS), X-Content-Type-Options, X-Frame-Options, Referrer-Policy,
+// Permissions-Policy and Content-Security-Policy.
+//
+// Each request gets a new nonce, available to templates as {{.cspNonce}},
+// which replaces {nonce} in "headers.csp", e.g.
+//   headers.csp = default-src 'self'; script-src 'self' 'nonce-{nonce}'
+//
+// The policy is only reported on, and not enforced, with
+// "headers.csp.report_only". Violations are reported to
+// "headers.csp.report_uri", which is answered by the filter itself, logging
+// the reports, if it is a path of the application. The filter should then
+// come before the RouterFilter.
+func SecureHeadersFilter(c *Controller, fc []Filter) {
+	if secureHeaders.cspReportUri != "" && c.Request.Method == "POST" &&
+		c.Request.URL.Path == secureHeaders.cspReportUri {
+		logCSPReport(c)
+		return
+	}
+
+	nonce := newCSPNonce()
+	c.RenderArgs[CSPNonceRenderArg] = nonce
+
+	header := c.Response.Out.Header()
+	if secureHeaders.hsts != "" && c.Request.Scheme() == "https" {
+		header.Set("Strict-Transport-Security", secureHeaders.hsts)
+	}
+	for name, value := range map[string]string{
+		"X-Content-Type-Options": secureHeaders.contentTypeOptions,
+		"X-Frame-Options":        secureHeaders.frameOptions,
+		"Referrer-Policy":        secureHeaders.referrerPolicy,
+		"Permissions-Policy":     secureHeaders.permissionsPolicy,
+	} {
+		if value != "" {
+			header.Set(name, value)
+		}
+	}
+	if secureHeaders.csp != "" {
+		name := "Content-Security-Policy"
+		if secureHeaders.cspReportOnly {
+			name = "Content-Security-Policy-Report-Only"
+		}
+		header.Set(name, strings.Replace(secureHeaders.csp, cspNoncePlaceholder, nonce, -1))
+	}
+
+	fc[0](c, fc[1:])
+}
+
+// logCSPReport logs a violation report sent by a browser.
+func logCSPReport(c *Controller) {
+	report, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxCSPReportSize))
+	if err != nil {
+		ERROR.Println("Failed to read the CSP report:", err)
+	} else {
+		WARN.Printf("Content-Security-Policy violation: %s", report)
+	}
+	c.Result = noContentResult{}
+}
+
+func newCSPNonce() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,85 @@
+package revel
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// secureHeadersTester runs the SecureHeadersFilter for a request, and reports
+// whether the rest of the chain was reached.
+func secureHeadersTester(method, path string, prepare func(req *http.Request)) (*Controller, bool) {
+	httpRequest, _ := http.NewRequest(method, "http://example.com"+path, strings.NewReader(`{"csp-report":{}}`))
+	if prepare != nil {
+		prepare(httpRequest)
+	}
+	c := NewController(NewRequest(httpRequest), NewResponse(httptest.NewRecorder()))
+
+	invoked := false
+	SecureHeadersFilter(c, []Filter{func(c *Controller, _ []Filter) {
+		invoked = true
+	}})
+	return c, invoked
+}
+
+func TestSecureHeadersFilter(t *testing.T) {
+	saved := secureHeaders
+	defer func() { secureHeaders = saved }()
+	secureHeaders.hsts = "max-age=31536000"
+	secureHeaders.frameOptions = "DENY"
+	secureHeaders.csp = "script-src 'self' 'nonce-{nonce}'"
+
+	c, invoked := secureHeadersTester("GET", "/", nil)
+	header := c.Response.Out.Header()
+	nonce, _ := c.RenderArgs[CSPNonceRenderArg].(string)
+	if !invoked || nonce == "" || header.Get("X-Frame-Options") != "DENY" {
+		t.Fatalf("Unexpected headers %v and nonce %q", header, nonce)
+	}
+	if csp := header.Get("Content-Security-Policy"); csp != "script-src 'self' 'nonce-"+nonce+"'" {
+		t.Errorf("Expected the nonce in the CSP, got %s", csp)
+	}
+	if header.Get("Strict-Transport-Security") != "" {
+		t.Errorf("Expected no HSTS over HTTP")
+	}
+
+	c, _ = secureHeadersTester("GET", "/", func(req *http.Request) {
+		req.TLS = &tls.ConnectionState{}
+	})
+	if c.Response.Out.Header().Get("Strict-Transport-Security") != "max-age=31536000" {
+		t.Errorf("Expected HSTS over HTTPS, got %v", c.Response.Out.Header())
+	}
+	if c.RenderArgs[CSPNonceRenderArg] == nonce {
+		t.Errorf("Expected a new nonce for each request")
+	}
+	// Behind a trusted proxy terminating TLS.
+	withTrustedProxies(t, []string{"10.0.0.0/8"}, func() {
+		c, _ = secureHeadersTester("GET", "/", func(req *http.Request) {
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-Proto", "https")
+		})
+		if c.Response.Out.Header().Get("Strict-Transport-Security") != "max-age=31536000" {
+			t.Errorf("Expected HSTS behind a proxy terminating TLS, got %v", c.Response.Out.Header())
+		}
+	})
+}
+
+func TestSecureHeadersCSPReport(t *testing.T) {
+	saved := secureHeaders
+	defer func() { secureHeaders = saved }()
+	secureHeaders.csp = "default-src 'self'; report-uri /csp-report"
+	secureHeaders.cspReportOnly = true
+	secureHeaders.cspReportUri = "/csp-report"
+
+	c, invoked := secureHeadersTester("GET", "/", nil)
+	if !invoked || c.Response.Out.Header().Get("Content-Security-Policy-Report-Only") == "" ||
+		c.Response.Out.Header().Get("Content-Security-Policy") != "" {
+		t.Errorf("Expected a report only policy, got %v", c.Response.Out.Header())
+	}
+
+	c, invoked = secureHeadersTester("POST", "/csp-report", nil)
+	if invoked || c.Result == nil {
+		t.Errorf("Expected the report to be answered by the filter")
+	}
+}
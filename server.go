@@ -44,6 +44,10 @@ func handleInternal(w http.ResponseWriter, r *http.Request, ws *websocket.Conn)
 
 	req.Websocket = ws
 
+	ctx, cancel := newRequestContext(req, resp)
+	defer cancel()
+	c.SetContext(ctx)
+
 	Filters[0](c, Filters[1:])
 
 	if c.Result != nil {
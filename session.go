@@ -2,6 +2,7 @@ package revel
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"net/http"
@@ -11,14 +12,18 @@ import (
 	"time"
 )
 
-// A signed cookie (and thus limited to 4kb in size).
+// A signed cookie (and thus limited to 4kb in size), unless a server side
+// SessionStore is used.
 // Restriction: Keys may not have a colon in them.
+// Values other than strings may be stored with Set and read with Get.
 type Session map[string]string
 
 const (
-	SESSION_ID_KEY     = "_ID"
-	SESSION_ID_KEY_LEN = 32
-	TIMESTAMP_KEY      = "_TS"
+	SESSION_ID_KEY       = "_ID"
+	SESSION_ID_KEY_LEN   = 32
+	TIMESTAMP_KEY        = "_TS"
+	SESSION_CREATED_KEY  = "_CT" // When the session was created, or regenerated.
+	SESSION_ACCESSED_KEY = "_AT" // When the session was last used.
 )
 
 // expireAfterDuration is the time to live, in seconds, of a session cookie.
@@ -27,6 +32,22 @@ const (
 // sets a session cookie.
 var expireAfterDuration time.Duration
 
+// sessionIdleTimeout and sessionAbsoluteTimeout end a session that has not
+// been used for, or was created longer ago than, the given duration. They
+// may be specified in config as "session.idle_timeout" and
+// "session.absolute_timeout", and are disabled by default.
+var sessionIdleTimeout, sessionAbsoluteTimeout time.Duration
+
+var sessionExpiredHooks []func(c *Controller, session Session, reason string)
+
+// OnSessionExpired registers a function to be called when the SessionFilter
+// ends a session because of the idle ("idle") or absolute ("absolute")
+// timeout, e.g. to log the forced logout. The function is given the expired
+// session; the request goes on with a new, empty session.
+func OnSessionExpired(f func(c *Controller, session Session, reason string)) {
+	sessionExpiredHooks = append(sessionExpiredHooks, f)
+}
+
 func init() {
 	// Set expireAfterDuration, default to 30 days if no value in config
 	OnAppStart(func() {
@@ -38,9 +59,24 @@ func init() {
 		} else if expireAfterDuration, err = time.ParseDuration(expiresString); err != nil {
 			panic(fmt.Errorf("session.expires invalid: %s", err))
 		}
+
+		sessionIdleTimeout = parseSessionTimeout("session.idle_timeout")
+		sessionAbsoluteTimeout = parseSessionTimeout("session.absolute_timeout")
 	})
 }
 
+func parseSessionTimeout(key string) time.Duration {
+	value, ok := Config.String(key)
+	if !ok || value == "" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		panic(fmt.Errorf("%s invalid: %s", key, err))
+	}
+	return timeout
+}
+
 // Id retrieves from the cookie or creates a time-based UUID identifying this
 // session.
 func (s Session) Id() string {
@@ -57,6 +93,41 @@ func (s Session) Id() string {
 	return s[SESSION_ID_KEY]
 }
 
+// Regenerate gives the session a new id, keeping its values. It should be
+// called when the privileges of the user change, e.g. after logging in, to
+// prevent session fixation. The previous id is removed from server side
+// stores, and the absolute timeout starts over.
+func (s Session) Regenerate() string {
+	delete(s, SESSION_ID_KEY)
+	delete(s, SESSION_CREATED_KEY)
+	return s.Id()
+}
+
+// expired returns why the session has timed out at the given time, or "".
+func (s Session) expired(now time.Time) string {
+	if sessionIdleTimeout > 0 {
+		if accessed, err := strconv.ParseInt(s[SESSION_ACCESSED_KEY], 10, 64); err == nil &&
+			now.Sub(time.Unix(accessed, 0)) > sessionIdleTimeout {
+			return "idle"
+		}
+	}
+	if sessionAbsoluteTimeout > 0 {
+		if created, err := strconv.ParseInt(s[SESSION_CREATED_KEY], 10, 64); err == nil &&
+			now.Sub(time.Unix(created, 0)) > sessionAbsoluteTimeout {
+			return "absolute"
+		}
+	}
+	return ""
+}
+
+// touch records the session as used at the given time.
+func (s Session) touch(now time.Time) {
+	if _, ok := s[SESSION_CREATED_KEY]; !ok {
+		s[SESSION_CREATED_KEY] = strconv.FormatInt(now.Unix(), 10)
+	}
+	s[SESSION_ACCESSED_KEY] = strconv.FormatInt(now.Unix(), 10)
+}
+
 // getExpiration return a time.Time with the session's expiration date.
 // If previous session has set to "session", remain it
 func (s Session) getExpiration() time.Time {
@@ -67,7 +138,7 @@ func (s Session) getExpiration() time.Time {
 	return time.Now().Add(expireAfterDuration)
 }
 
-// cookie returns an http.Cookie containing the signed session.
+// cookie returns an http.Cookie containing the signed, or encrypted, session.
 func (s Session) cookie() *http.Cookie {
 	var sessionValue string
 	ts := s.getExpiration()
@@ -82,15 +153,21 @@ func (s Session) cookie() *http.Cookie {
 		sessionValue += "\x00" + key + ":" + value + "\x00"
 	}
 
-	sessionData := url.QueryEscape(sessionValue)
-	return &http.Cookie{
-		Name:     CookiePrefix + "_SESSION",
-		Value:    Sign(sessionData) + "-" + sessionData,
-		Path:     "/",
-		HttpOnly: CookieHttpOnly,
-		Secure:   CookieSecure,
-		Expires:  ts.UTC(),
-	}
+	return sessionCookie(protectCookieValue(sessionCookiePurpose, url.QueryEscape(sessionValue)), ts)
+}
+
+// sessionCookie returns the session cookie with the given value.
+func sessionCookie(value string, expires time.Time) *http.Cookie {
+	cookie := NewCookie(CookiePrefix+"_SESSION", value)
+	cookie.Expires = expires.UTC()
+	return cookie
+}
+
+// expiredSessionCookie returns a cookie removing the session cookie.
+func expiredSessionCookie() *http.Cookie {
+	cookie := sessionCookie("", time.Time{})
+	cookie.MaxAge = -1
+	return cookie
 }
 
 // sessionTimeoutExpiredOrMissing returns a boolean of whether the session
@@ -107,20 +184,13 @@ func sessionTimeoutExpiredOrMissing(session Session) bool {
 	return false
 }
 
-// getSessionFromCookie returns a Session struct pulled from the signed
-// session cookie.
+// getSessionFromCookie returns a Session struct pulled from the signed, or
+// encrypted, session cookie.
 func getSessionFromCookie(cookie *http.Cookie) Session {
 	session := make(Session)
 
-	// Separate the data from the signature.
-	hyphen := strings.Index(cookie.Value, "-")
-	if hyphen == -1 || hyphen >= len(cookie.Value)-1 {
-		return session
-	}
-	sig, data := cookie.Value[:hyphen], cookie.Value[hyphen+1:]
-
-	// Verify the signature.
-	if !Verify(data, sig) {
+	data, ok := unprotectCookieValue(sessionCookiePurpose, cookie.Value)
+	if !ok {
 		INFO.Println("Session cookie signature failed")
 		return session
 	}
@@ -136,22 +206,94 @@ func getSessionFromCookie(cookie *http.Cookie) Session {
 	return session
 }
 
+// The purposes of the cookies protected with protectCookieValue, which their
+// signatures and ciphertexts are bound to, so that the value of one cookie, e.g. the flash
+// with its data taken from the request, is not valid as another.
+const (
+	sessionCookiePurpose = "session"
+	flashCookiePurpose   = "flash"
+)
+
+// protectCookieValue returns the value of the cookie of the given purpose for
+// the data, encrypted if cookie.encrypt is set, or else signed.
+func protectCookieValue(purpose, data string) string {
+	if CookieEncrypt {
+		value, err := encrypt(data, purpose)
+		if err != nil {
+			panic(err)
+		}
+		return value
+	}
+	return signCookieValue(purpose, data) + "-" + data
+}
+
+// unprotectCookieValue returns the data of a cookie value made with
+// protectCookieValue for the same purpose, after decrypting it or verifying
+// its signature. Signed cookies are accepted with encryption enabled, so that
+// turning it on does not invalidate the existing sessions.
+func unprotectCookieValue(purpose, value string) (string, bool) {
+	if CookieEncrypt {
+		if data, err := decrypt(value, purpose); err == nil {
+			return data, true
+		}
+	}
+
+	// Separate the data from the signature.
+	hyphen := strings.Index(value, "-")
+	if hyphen == -1 || hyphen >= len(value)-1 {
+		return "", false
+	}
+	sig, data := value[:hyphen], value[hyphen+1:]
+
+	// Verify the signature.
+	if !verifyCookieValue(purpose, data, sig) {
+		return "", false
+	}
+	return data, true
+}
+
+// signCookieValue returns the signature of the data of a cookie of the given
+// purpose.
+func signCookieValue(purpose, data string) string {
+	return Sign(purpose + "\x00" + data)
+}
+
+// verifyCookieValue returns true if the signature of the data was made with
+// signCookieValue for the same purpose. The HMAC-SHA1 signatures of earlier
+// versions of Revel were not bound to a purpose, but only signed sessions.
+func verifyCookieValue(purpose, data, sig string) bool {
+	return verify(sha256.New, purpose+"\x00"+data, sig) || verifySHA1(data, sig)
+}
+
 // SessionFilter is a Revel Filter that retrieves and sets the session cookie.
 // Within Revel, it is available as a Session attribute on Controller instances.
 // The name of the Session cookie is set as CookiePrefix + "_SESSION".
+// The session is kept in the SessionStore selected with "session.store".
 func SessionFilter(c *Controller, fc []Filter) {
-	c.Session = restoreSession(c.Request.Request)
+	store := currentSessionStore()
+	c.Session = store.Restore(c)
 
 	isEmptySession := len(c.Session) == 0
 
+	// End the session if it timed out. It is then removed from the store.
+	if reason := c.Session.expired(time.Now()); !isEmptySession && reason != "" {
+		for _, hook := range sessionExpiredHooks {
+			hook(c, c.Session, reason)
+		}
+		c.Session = make(Session)
+	}
+
 	// Make session vars available in templates as {{.session.xyz}}
 	c.RenderArgs["session"] = c.Session
 
 	fc[0](c, fc[1:])
 
-	// Store the signed session if it is not empty or have changed.
+	// Store the session if it is not empty or have changed.
 	if !isEmptySession || len(c.Session) > 0 {
-		c.SetCookie(c.Session.cookie())
+		if len(c.Session) > 0 {
+			c.Session.touch(time.Now())
+		}
+		store.Save(c, c.Session)
 	}
 }
 
@@ -0,0 +1,228 @@
+package revel
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The record saved by the file and sql session backends.
+type storedSession struct {
+	Session Session
+	Expires int64
+}
+
+// FileSessionBackend keeps each session in a JSON file named after its id.
+type FileSessionBackend struct {
+	Dir string
+}
+
+// NewFileSessionBackend returns a backend keeping sessions in dir, relative
+// to BasePath unless absolute.
+func NewFileSessionBackend(dir string) FileSessionBackend {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(BasePath, dir)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		ERROR.Println("Failed to create the session directory:", err)
+	}
+	return FileSessionBackend{dir}
+}
+
+func (b FileSessionBackend) Get(id string) (Session, error) {
+	stored, err := b.read(b.path(id))
+	if os.IsNotExist(err) {
+		return nil, ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if stored.Expires < time.Now().Unix() {
+		return nil, ErrSessionNotFound
+	}
+	return stored.Session, nil
+}
+
+func (b FileSessionBackend) Set(id string, session Session, expires time.Time) error {
+	data, err := json.Marshal(storedSession{session, expires.Unix()})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that readers never see a partial
+	// session.
+	tmp, err := ioutil.TempFile(b.Dir, "tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), b.path(id))
+}
+
+func (b FileSessionBackend) Delete(id string) error {
+	if err := os.Remove(b.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (b FileSessionBackend) DeleteUser(user string) error {
+	return b.deleteWhere(func(stored storedSession) bool {
+		return stored.Session[SESSION_USER_KEY] == user
+	})
+}
+
+func (b FileSessionBackend) Sweep() error {
+	now := time.Now().Unix()
+	return b.deleteWhere(func(stored storedSession) bool {
+		return stored.Expires < now
+	})
+}
+
+// deleteWhere removes the sessions for which the given function returns true.
+func (b FileSessionBackend) deleteWhere(fn func(stored storedSession) bool) error {
+	files, err := ioutil.ReadDir(b.Dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if !isSessionId(file.Name()) {
+			continue
+		}
+		path := filepath.Join(b.Dir, file.Name())
+		if stored, err := b.read(path); err != nil || fn(stored) {
+			if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b FileSessionBackend) read(path string) (stored storedSession, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &stored)
+	return
+}
+
+func (b FileSessionBackend) path(id string) string {
+	return filepath.Join(b.Dir, id)
+}
+
+// SQLSessionBackend keeps the sessions in a database table:
+//
+//   CREATE TABLE revel_sessions (
+//     id      VARCHAR(64) PRIMARY KEY,
+//     user_id VARCHAR(255) NOT NULL,
+//     data    TEXT NOT NULL,
+//     expires BIGINT NOT NULL
+//   );
+type SQLSessionBackend struct {
+	Db    *sql.DB
+	Table string
+
+	// Whether the driver uses $1, $2... placeholders (e.g. postgres) rather
+	// than ?.
+	NumberedPlaceholders bool
+}
+
+// NewSQLSessionBackend opens the database with the given driver and spec.
+// The driver must have been imported by the application.
+func NewSQLSessionBackend(driver, spec, table string) (SQLSessionBackend, error) {
+	if driver == "" {
+		return SQLSessionBackend{}, errors.New("no session.sql.driver found")
+	}
+	db, err := sql.Open(driver, spec)
+	if err != nil {
+		return SQLSessionBackend{}, err
+	}
+	return SQLSessionBackend{
+		Db:                   db,
+		Table:                table,
+		NumberedPlaceholders: driver == "postgres" || driver == "pgx",
+	}, nil
+}
+
+func (b SQLSessionBackend) Get(id string) (Session, error) {
+	var data string
+	err := b.Db.QueryRow(b.query("SELECT data FROM %s WHERE id = ? AND expires >= ?"),
+		id, time.Now().Unix()).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	session := make(Session)
+	if err = json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (b SQLSessionBackend) Set(id string, session Session, expires time.Time) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	tx, err := b.Db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(b.query("DELETE FROM %s WHERE id = ?"), id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(b.query("INSERT INTO %s (id, user_id, data, expires) VALUES (?, ?, ?, ?)"),
+		id, session[SESSION_USER_KEY], string(data), expires.Unix()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (b SQLSessionBackend) Delete(id string) error {
+	_, err := b.Db.Exec(b.query("DELETE FROM %s WHERE id = ?"), id)
+	return err
+}
+
+func (b SQLSessionBackend) DeleteUser(user string) error {
+	_, err := b.Db.Exec(b.query("DELETE FROM %s WHERE user_id = ?"), user)
+	return err
+}
+
+func (b SQLSessionBackend) Sweep() error {
+	_, err := b.Db.Exec(b.query("DELETE FROM %s WHERE expires < ?"), time.Now().Unix())
+	return err
+}
+
+// query fills in the table name, and numbers the placeholders if needed.
+func (b SQLSessionBackend) query(query string) string {
+	query = strings.Replace(query, "%s", b.Table, 1)
+	if !b.NumberedPlaceholders {
+		return query
+	}
+
+	parts := strings.Split(query, "?")
+	query = parts[0]
+	for i, part := range parts[1:] {
+		query += "$" + strconv.Itoa(i+1) + part
+	}
+	return query
+}
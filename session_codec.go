@@ -0,0 +1,105 @@
+package revel
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// A SessionCodec encodes the values stored with Session.Set into strings.
+type SessionCodec interface {
+	Encode(value interface{}) (string, error)
+	Decode(data string, ptr interface{}) error
+}
+
+// ErrSessionKeyNotFound is returned by Session.Get for a missing key.
+var ErrSessionKeyNotFound = errors.New("revel: session key not found")
+
+var (
+	// The codecs available for "session.codec".
+	SessionCodecs = map[string]SessionCodec{
+		"json": JSONSessionCodec{},
+		"gob":  GobSessionCodec{},
+	}
+
+	// The codec used by Session.Set and Session.Get, "json" by default.
+	DefaultSessionCodec SessionCodec = JSONSessionCodec{}
+)
+
+func init() {
+	OnAppStart(func() {
+		name := Config.StringDefault("session.codec", "json")
+		codec, ok := SessionCodecs[name]
+		if !ok {
+			ERROR.Fatalln("Unknown session.codec:", name)
+		}
+		DefaultSessionCodec = codec
+	})
+}
+
+// Set stores the given value in the session, encoded with the session codec.
+// Strings are stored as they are, so that they can be read directly, e.g.
+// with session["key"].
+func (s Session) Set(key string, value interface{}) error {
+	if str, ok := value.(string); ok {
+		s[key] = str
+		return nil
+	}
+
+	data, err := DefaultSessionCodec.Encode(value)
+	if err != nil {
+		return err
+	}
+	s[key] = data
+	return nil
+}
+
+// Get decodes the value stored in the session with Set into the given
+// pointer. It returns ErrSessionKeyNotFound if there is no such value.
+func (s Session) Get(key string, ptr interface{}) error {
+	data, ok := s[key]
+	if !ok {
+		return ErrSessionKeyNotFound
+	}
+
+	if str, ok := ptr.(*string); ok {
+		*str = data
+		return nil
+	}
+	return DefaultSessionCodec.Decode(data, ptr)
+}
+
+// JSONSessionCodec encodes session values as JSON.
+type JSONSessionCodec struct{}
+
+func (c JSONSessionCodec) Encode(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+func (c JSONSessionCodec) Decode(data string, ptr interface{}) error {
+	return json.Unmarshal([]byte(data), ptr)
+}
+
+// GobSessionCodec encodes session values with encoding/gob, in base64.
+// Types stored in an interface must be registered with gob.Register.
+type GobSessionCodec struct{}
+
+func (c GobSessionCodec) Encode(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).EncodeValue(reflect.ValueOf(value)); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func (c GobSessionCodec) Decode(data string, ptr interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(bytes.NewReader(b)).Decode(ptr)
+}
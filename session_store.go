@@ -0,0 +1,307 @@
+package revel
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// The key of the user a session belongs to, see Session.SetUser.
+	SESSION_USER_KEY = "_USER"
+
+	// How long a server side session is kept when its cookie expires with
+	// the browser session.
+	DEFAULT_SESSION_STORE_EXPIRES = 24 * time.Hour
+)
+
+// ErrSessionNotFound is returned by a SessionBackend for an unknown session id.
+var ErrSessionNotFound = errors.New("revel: session not found")
+
+// A SessionStore restores the Session at the start of a request, and saves
+// it at the end of the request.
+//
+// The store is selected with "session.store" in app.conf:
+//   cookie - the whole session is kept in the signed session cookie (default)
+//   cache  - in the cache (requires the cache package)
+//   file   - in the directory "session.file.dir"
+//   sql    - in the table "session.sql.table", using "session.sql.driver"
+//            and "session.sql.spec"
+// With a server side store, the session cookie only holds the session id.
+type SessionStore interface {
+	// Restore returns the session of the request, or a new, empty session.
+	Restore(c *Controller) Session
+
+	// Save saves the session after the request. An empty session has been
+	// destroyed, and must be removed.
+	Save(c *Controller, session Session)
+
+	// DestroyUser removes all of the sessions of the given user.
+	DestroyUser(user string) error
+}
+
+// A SessionBackend keeps sessions on the server for a ServerSessionStore.
+type SessionBackend interface {
+	// Get returns the session with the given id, or ErrSessionNotFound.
+	Get(id string) (Session, error)
+
+	// Set saves the session under the given id until expires.
+	Set(id string, session Session, expires time.Time) error
+
+	// Delete removes the session with the given id.
+	Delete(id string) error
+
+	// DeleteUser removes all the sessions with the given SESSION_USER_KEY.
+	DeleteUser(user string) error
+
+	// Sweep removes the expired sessions.
+	Sweep() error
+}
+
+var (
+	sessionStores = map[string]SessionStore{
+		"cookie": CookieSessionStore{},
+	}
+
+	sessionStoreName = "cookie"
+)
+
+func init() {
+	OnAppStart(func() {
+		sessionStoreName = Config.StringDefault("session.store", "cookie")
+
+		switch sessionStoreName {
+		case "file":
+			RegisterSessionStore("file", NewServerSessionStore(
+				NewFileSessionBackend(Config.StringDefault("session.file.dir", "sessions"))))
+		case "sql":
+			backend, err := NewSQLSessionBackend(
+				Config.StringDefault("session.sql.driver", ""),
+				Config.StringDefault("session.sql.spec", ""),
+				Config.StringDefault("session.sql.table", "revel_sessions"))
+			if err != nil {
+				ERROR.Fatalln("Failed to open the session database:", err)
+			}
+			RegisterSessionStore("sql", NewServerSessionStore(backend))
+		}
+
+		// Periodically remove the expired sessions of server side stores.
+		if store, ok := currentSessionStore().(ServerSessionStore); ok {
+			interval, err := time.ParseDuration(Config.StringDefault("session.sweep_interval", "1h"))
+			if err != nil {
+				ERROR.Fatalln("session.sweep_interval invalid:", err)
+			}
+			go func() {
+				for range time.Tick(interval) {
+					if err := store.Backend.Sweep(); err != nil {
+						ERROR.Println("Failed to sweep expired sessions:", err)
+					}
+				}
+			}()
+		}
+	})
+
+	RegisterValidationStore("session", SessionValidationStore{})
+}
+
+// RegisterSessionStore makes a SessionStore available under the given name
+// for use in "session.store".
+func RegisterSessionStore(name string, store SessionStore) {
+	sessionStores[name] = store
+}
+
+// currentSessionStore returns the store selected in the configuration.
+func currentSessionStore() SessionStore {
+	if store, ok := sessionStores[sessionStoreName]; ok {
+		return store
+	}
+	WARN.Printf("Unknown session.store %s, using cookie", sessionStoreName)
+	return sessionStores["cookie"]
+}
+
+// DestroyUserSessions logs the given user out of all of their sessions, i.e.
+// removes all the sessions on which SetUser(user) was called.
+// It is not supported by the cookie store.
+func DestroyUserSessions(user string) error {
+	return currentSessionStore().DestroyUser(user)
+}
+
+// SetUser records the user the session belongs to, for DestroyUserSessions.
+func (s Session) SetUser(user string) {
+	s[SESSION_USER_KEY] = user
+}
+
+// Destroy removes all the values of the session, including its id. The
+// session is then removed from the store, and its cookie from the client.
+func (s Session) Destroy() {
+	for key := range s {
+		delete(s, key)
+	}
+}
+
+// CookieSessionStore keeps the whole session in the signed session cookie.
+type CookieSessionStore struct{}
+
+func (s CookieSessionStore) Restore(c *Controller) Session {
+	return restoreSession(c.Request.Request)
+}
+
+func (s CookieSessionStore) Save(c *Controller, session Session) {
+	if len(session) == 0 {
+		c.SetCookie(expiredSessionCookie())
+		return
+	}
+	c.SetCookie(session.cookie())
+}
+
+func (s CookieSessionStore) DestroyUser(user string) error {
+	return errors.New("revel: cookie sessions can not be destroyed on the server")
+}
+
+// ServerSessionStore keeps the session in a SessionBackend, and only its id
+// in the signed session cookie.
+type ServerSessionStore struct {
+	Backend SessionBackend
+}
+
+func NewServerSessionStore(backend SessionBackend) ServerSessionStore {
+	return ServerSessionStore{backend}
+}
+
+func (s ServerSessionStore) Restore(c *Controller) Session {
+	id := sessionIdFromCookie(c.Request.Request)
+	if id == "" {
+		return make(Session)
+	}
+
+	session, err := s.Backend.Get(id)
+	if err != nil {
+		if err != ErrSessionNotFound {
+			ERROR.Println("Failed to restore session:", err)
+		}
+		return make(Session)
+	}
+	if session[SESSION_ID_KEY] != id || sessionTimeoutExpiredOrMissing(session) {
+		return make(Session)
+	}
+	return session
+}
+
+func (s ServerSessionStore) Save(c *Controller, session Session) {
+	previousId := sessionIdFromCookie(c.Request.Request)
+	if len(session) == 0 {
+		if previousId != "" {
+			if err := s.Backend.Delete(previousId); err != nil {
+				ERROR.Println("Failed to destroy session:", err)
+			}
+		}
+		c.SetCookie(expiredSessionCookie())
+		return
+	}
+
+	// Remove the previous session when the id has changed.
+	id := session.Id()
+	if previousId != "" && previousId != id {
+		if err := s.Backend.Delete(previousId); err != nil {
+			ERROR.Println("Failed to remove the previous session:", err)
+		}
+	}
+
+	ts := session.getExpiration()
+	session[TIMESTAMP_KEY] = getSessionExpirationCookie(ts)
+	expires := ts
+	if expires.IsZero() {
+		expires = time.Now().Add(DEFAULT_SESSION_STORE_EXPIRES)
+	}
+	if err := s.Backend.Set(id, session, expires); err != nil {
+		ERROR.Println("Failed to save session:", err)
+		return
+	}
+
+	c.SetCookie(sessionCookie(signCookieValue(sessionCookiePurpose, id)+"-"+id, ts))
+}
+
+func (s ServerSessionStore) DestroyUser(user string) error {
+	return s.Backend.DeleteUser(user)
+}
+
+// sessionIdFromCookie returns the verified session id from the session
+// cookie of a server side store, or "" if there is none.
+func sessionIdFromCookie(req *http.Request) string {
+	cookie, err := req.Cookie(CookiePrefix + "_SESSION")
+	if err != nil {
+		return ""
+	}
+
+	hyphen := strings.Index(cookie.Value, "-")
+	if hyphen == -1 || hyphen >= len(cookie.Value)-1 {
+		return ""
+	}
+	sig, id := cookie.Value[:hyphen], cookie.Value[hyphen+1:]
+	if !verifyCookieValue(sessionCookiePurpose, id, sig) || !isSessionId(id) {
+		INFO.Println("Session cookie signature failed")
+		return ""
+	}
+	return id
+}
+
+// isSessionId reports whether id looks like one generated by Session.Id,
+// which backends rely on to use it in file names and keys.
+func isSessionId(id string) bool {
+	if len(id) != 2*SESSION_ID_KEY_LEN {
+		return false
+	}
+	for _, c := range id {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// SessionValidationStore keeps the kept validation errors and flashed params
+// in the session, which is useful with a server side session store.
+type SessionValidationStore struct{}
+
+const (
+	sessionErrorsKey = "_ERRORS"
+	sessionParamsKey = "_PARAMS"
+)
+
+func (s SessionValidationStore) Restore(c *Controller) ([]*ValidationError, map[string]string) {
+	var (
+		errors []*ValidationError
+		params = make(map[string]string)
+	)
+	ParseKeyValueCookie(c.Session[sessionErrorsKey], func(key, val string) {
+		errors = append(errors, &ValidationError{
+			Key:     key,
+			Message: val,
+		})
+	})
+	ParseKeyValueCookie(c.Session[sessionParamsKey], func(key, val string) {
+		params[key] = val
+	})
+	return errors, params
+}
+
+func (s SessionValidationStore) Store(c *Controller, errors []*ValidationError, params map[string]string) {
+	delete(c.Session, sessionErrorsKey)
+	delete(c.Session, sessionParamsKey)
+
+	var errorsValue, paramsValue string
+	for _, error := range errors {
+		errorsValue += "\x00" + error.Key + ":" + error.Message + "\x00"
+	}
+	for key, value := range params {
+		paramsValue += "\x00" + key + ":" + value + "\x00"
+	}
+	if errorsValue != "" {
+		c.Session[sessionErrorsKey] = url.QueryEscape(errorsValue)
+	}
+	if paramsValue != "" {
+		c.Session[sessionParamsKey] = url.QueryEscape(paramsValue)
+	}
+}
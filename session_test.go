@@ -1,7 +1,12 @@
 package revel
 
 import (
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -62,3 +67,216 @@ func TestSessionExpire(t *testing.T) {
 		t.Error("expect expires", cookie.Expires, "before", expectExpire)
 	}
 }
+
+// sessionTester runs the SessionFilter for a request with the given session
+// cookie, and returns the session cookie of the response.
+func sessionTester(t *testing.T, cookie *http.Cookie, fn func(c *Controller)) *http.Cookie {
+	req := buildEmptyRequest()
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	c := NewController(req, NewResponse(recorder))
+	SessionFilter(c, []Filter{func(c *Controller, _ []Filter) {
+		fn(c)
+	}})
+	cookie, err := getRecordedCookie(recorder, CookiePrefix+"_SESSION")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cookie
+}
+
+func TestServerSessionStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "revel-sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	expireAfterDuration = time.Hour
+	backend := NewFileSessionBackend(dir)
+	RegisterSessionStore("test", NewServerSessionStore(backend))
+	sessionStoreName = "test"
+	defer func() { sessionStoreName = "cookie" }()
+
+	login := func() *http.Cookie {
+		return sessionTester(t, nil, func(c *Controller) {
+			c.Session["name"] = "Tom"
+			c.Session.SetUser("tom")
+		})
+	}
+
+	cookie := login()
+	if id := sessionIdFromCookie(&http.Request{Header: http.Header{"Cookie": {cookie.String()}}}); id == "" {
+		t.Errorf("Expected the cookie to only hold the signed session id, got %s", cookie.Value)
+	}
+	sessionTester(t, cookie, func(c *Controller) {
+		if c.Session["name"] != "Tom" {
+			t.Errorf("Expected the session to be restored, got %v", c.Session)
+		}
+	})
+
+	// A destroyed session is removed along with its cookie.
+	cookie = sessionTester(t, cookie, func(c *Controller) {
+		c.Session.Destroy()
+	})
+	if cookie.MaxAge >= 0 {
+		t.Errorf("Expected the session cookie to be removed")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected the session to be removed, got %d files", len(files))
+	}
+
+	// Log out all the sessions of a user.
+	first, second := login(), login()
+	if err = DestroyUserSessions("tom"); err != nil {
+		t.Fatal(err)
+	}
+	for _, cookie := range []*http.Cookie{first, second} {
+		sessionTester(t, cookie, func(c *Controller) {
+			if len(c.Session) != 0 {
+				t.Errorf("Expected the session to be destroyed, got %v", c.Session)
+			}
+			c.Session["name"] = "Anonymous"
+		})
+	}
+
+	// Expired sessions are swept.
+	backend.Set(Session{}.Id(), Session{"name": "Old"}, time.Now().Add(-time.Minute))
+	if err = backend.Sweep(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 2 {
+		t.Errorf("Expected only the 2 anonymous sessions to be left, got %d files", len(files))
+	}
+}
+
+func TestSessionEncryption(t *testing.T) {
+	withSecrets("secret", nil, func() {
+		CookieEncrypt = true
+		defer func() { CookieEncrypt = false }()
+
+		expireAfterDuration = time.Hour
+		session := Session{"user": "Tom"}
+		cookie := session.cookie()
+		if strings.Contains(cookie.Value, "Tom") {
+			t.Errorf("Expected the session to be encrypted, got %s", cookie.Value)
+		}
+		if restored := getSessionFromCookie(cookie); restored["user"] != "Tom" {
+			t.Errorf("Expected the encrypted session to be restored, got %v", restored)
+		}
+
+		// Sessions signed before encryption was turned on are still valid.
+		CookieEncrypt = false
+		cookie = session.cookie()
+		CookieEncrypt = true
+		if restored := getSessionFromCookie(cookie); restored["user"] != "Tom" {
+			t.Errorf("Expected the signed session to be restored, got %v", restored)
+		}
+	})
+}
+
+type sessionCart struct {
+	Items    []string
+	Quantity map[string]int
+}
+
+func TestSessionTypedValues(t *testing.T) {
+	defer func() { DefaultSessionCodec = JSONSessionCodec{} }()
+
+	for name, codec := range SessionCodecs {
+		DefaultSessionCodec = codec
+		session := make(Session)
+		if err := session.Set("cart", sessionCart{[]string{"a:b"}, map[string]int{"a:b": 2}}); err != nil {
+			t.Fatal(name, err)
+		}
+		session.Set("uid", int64(42))
+		session.Set("name", "Tom")
+
+		// Round trip through the signed cookie.
+		expireAfterDuration = time.Hour
+		session = getSessionFromCookie(session.cookie())
+
+		var cart sessionCart
+		if err := session.Get("cart", &cart); err != nil || cart.Quantity["a:b"] != 2 {
+			t.Errorf("%s: expected the cart to be restored, got %v, %v", name, cart, err)
+		}
+		var uid int64
+		if err := session.Get("uid", &uid); err != nil || uid != 42 {
+			t.Errorf("%s: expected uid 42, got %d, %v", name, uid, err)
+		}
+		if session["name"] != "Tom" {
+			t.Errorf("%s: expected strings to be stored as they are, got %s", name, session["name"])
+		}
+		var missing string
+		if err := session.Get("missing", &missing); err != ErrSessionKeyNotFound {
+			t.Errorf("%s: expected ErrSessionKeyNotFound, got %v", name, err)
+		}
+	}
+}
+
+func TestSessionRegenerate(t *testing.T) {
+	session := Session{"user": "Tom"}
+	id := session.Id()
+	session.touch(time.Now())
+	if newId := session.Regenerate(); newId == id || session.Id() != newId {
+		t.Errorf("Expected a new session id, got %s", newId)
+	}
+	if session["user"] != "Tom" {
+		t.Errorf("Expected the session values to be kept, got %v", session)
+	}
+	if _, ok := session[SESSION_CREATED_KEY]; ok {
+		t.Errorf("Expected the absolute timeout to start over")
+	}
+}
+
+func TestSessionTimeouts(t *testing.T) {
+	expireAfterDuration = time.Hour
+	sessionIdleTimeout, sessionAbsoluteTimeout = 10*time.Minute, time.Hour
+	defer func() {
+		sessionIdleTimeout, sessionAbsoluteTimeout = 0, 0
+		sessionExpiredHooks = nil
+	}()
+
+	var expired string
+	OnSessionExpired(func(c *Controller, session Session, reason string) {
+		if session["user"] != "Tom" {
+			t.Errorf("Expected the expired session, got %v", session)
+		}
+		expired = reason
+	})
+
+	now := time.Now()
+	for _, test := range []struct {
+		created, accessed time.Duration
+		reason            string
+	}{
+		{30 * time.Minute, time.Minute, ""},
+		{30 * time.Minute, 11 * time.Minute, "idle"},
+		{2 * time.Hour, time.Minute, "absolute"},
+	} {
+		session := Session{"user": "Tom"}
+		session[SESSION_CREATED_KEY] = strconv.FormatInt(now.Add(-test.created).Unix(), 10)
+		session[SESSION_ACCESSED_KEY] = strconv.FormatInt(now.Add(-test.accessed).Unix(), 10)
+
+		expired = ""
+		cookie := sessionTester(t, session.cookie(), func(c *Controller) {
+			if (c.Session["user"] == "Tom") != (test.reason == "") {
+				t.Errorf("Unexpected session %v for expiry %q", c.Session, test.reason)
+			}
+		})
+		if expired != test.reason {
+			t.Errorf("Expected the session to expire with %q, got %q", test.reason, expired)
+		}
+		if test.reason != "" && cookie.MaxAge >= 0 {
+			t.Errorf("Expected the session cookie of an expired session to be removed")
+		}
+		if test.reason == "" {
+			accessed, _ := strconv.ParseInt(getSessionFromCookie(cookie)[SESSION_ACCESSED_KEY], 10, 64)
+			if time.Now().Unix()-accessed > 1 {
+				t.Errorf("Expected the session access time to be updated, got %d", accessed)
+			}
+		}
+	}
+}
@@ -77,6 +77,11 @@ var (
 			return template.HTML("")
 		},
 		"field": NewField,
+		// Renders the hidden field carrying the CSRF token, e.g. {{csrfField .}}
+		"csrfField": csrfFieldHTML,
+		// Returns the flash messages in order, e.g. {{range flashes .}}, or only
+		// those of some levels, e.g. {{range flashes . "error" "warning"}}
+		"flashes": flashesHelper,
 		"option": func(f *Field, val, label string) template.HTML {
 			selected := ""
 			if f.Flash() == val {
@@ -1,6 +1,7 @@
 greeting=Hallo 
 greeting.name=Rob
 greeting.suffix=, welkom bij Revel!
+validation.min_size=Minimale lengte is %v
 
 [NL]
 greeting=Goeiedag
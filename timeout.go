@@ -0,0 +1,223 @@
+package revel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// The timeout configured in app.conf with "http.timeout", none if 0.
+	defaultTimeout time.Duration
+
+	// The status of the responses to the requests timing out, 503 Service
+	// Unavailable by default.
+	timeoutStatus = http.StatusServiceUnavailable
+
+	// Map from "Controller" or "Controller.Method" to its timeout.
+	actionTimeouts = make(map[string]time.Duration)
+)
+
+func init() {
+	OnAppStart(func() {
+		var err error
+		defaultTimeout, err = time.ParseDuration(Config.StringDefault("http.timeout", "0"))
+		if err != nil {
+			ERROR.Fatalln("http.timeout invalid:", err)
+		}
+		timeoutStatus = Config.IntDefault("http.timeout.status", http.StatusServiceUnavailable)
+	})
+}
+
+// Timeout sets the timeout of the TimeoutFilter for the controller or action,
+// in place of "http.timeout". A timeout of 0 disables it. For example:
+//   revel.FilterAction(Reports.Export).Timeout(2 * time.Minute)
+func (conf FilterConfigurator) Timeout(timeout time.Duration) FilterConfigurator {
+	actionTimeouts[conf.key] = timeout
+	return conf
+}
+
+// TimeoutFilter is a Revel Filter that responds with an error page, 503
+// Service Unavailable or the "http.timeout.status" configured in app.conf, to
+// the requests taking longer than "http.timeout", or the timeout of the action
+// set with FilterConfigurator.Timeout. The context of the request is then
+// cancelled, and what the action writes afterwards is discarded.
+//
+// The rest of the chain runs in its own goroutine, on a copy of the
+// controller, and its response is buffered until it completes. The filter
+// must come right after the FilterConfiguringFilter, since the filters before
+// it may not touch the controller once it returns on a timeout.
+func TimeoutFilter(c *Controller, fc []Filter) {
+	timeout := actionTimeout(c)
+	if timeout <= 0 || c.Request.Websocket != nil {
+		fc[0](c, fc[1:])
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), timeout)
+	defer cancel()
+
+	var (
+		writer = &timeoutWriter{header: c.Response.Out.Header().Clone()}
+		tc     = *c
+		done   = make(chan struct{})
+	)
+	tc.Response = &Response{Out: writer}
+	tc.Args = copyArgs(c.Args)
+	tc.RenderArgs = copyArgs(c.RenderArgs)
+	tc.SetContext(ctx)
+
+	go func() {
+		defer close(done)
+		defer writer.complete()
+		runTimedChain(&tc, fc)
+	}()
+
+	var timedOut bool
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// Respond without waiting for the chain, unless it just completed.
+		if timedOut = writer.timeout(); !timedOut {
+			<-done
+		}
+	}
+	if timedOut {
+		c.Result = timeoutResult(c, timeout)
+		return
+	}
+	c.Result = bufferedResult{writer}
+}
+
+func timeoutResult(c *Controller, timeout time.Duration) Result {
+	WARN.Printf("%s timed out after %s", c.Action, timeout)
+	c.Response.Status = timeoutStatus
+	return c.RenderError(&Error{
+		Title:       http.StatusText(timeoutStatus),
+		Description: "The request took too long, retry later",
+	})
+}
+
+// runTimedChain runs the chain, and applies its result, as the server does.
+// Its panics are handled as by the PanicFilter.
+func runTimedChain(c *Controller, fc []Filter) {
+	defer func() {
+		if w, ok := c.Response.Out.(io.Closer); ok {
+			w.Close()
+		}
+	}()
+	defer func() {
+		if err := recover(); err != nil {
+			handleInvocationPanic(c, err)
+			if c.Result != nil {
+				c.Result.Apply(c.Request, c.Response)
+			}
+		}
+	}()
+
+	fc[0](c, fc[1:])
+
+	if c.Result != nil {
+		c.Result.Apply(c.Request, c.Response)
+	} else if c.Response.Status != 0 {
+		c.Response.Out.WriteHeader(c.Response.Status)
+	}
+}
+
+// actionTimeout returns the timeout of the action, or else of the controller,
+// or else the configured one.
+func actionTimeout(c *Controller) time.Duration {
+	if timeout, ok := actionTimeouts[c.Action]; ok {
+		return timeout
+	}
+	if timeout, ok := actionTimeouts[c.Name]; ok {
+		return timeout
+	}
+	return defaultTimeout
+}
+
+func copyArgs(args map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		copied[key] = value
+	}
+	return copied
+}
+
+// timeoutWriter buffers the response of the chain run by the TimeoutFilter,
+// and discards the writes once it timed out.
+type timeoutWriter struct {
+	mutex     sync.Mutex
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	timedOut  bool
+	completed bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if !w.timedOut && w.status == 0 {
+		w.status = status
+	}
+}
+
+// timeout makes the writer discard the later writes, and returns true, unless
+// the response is already complete.
+func (w *timeoutWriter) timeout() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.completed {
+		return false
+	}
+	w.timedOut = true
+	return true
+}
+
+func (w *timeoutWriter) complete() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.completed = true
+}
+
+// bufferedResult writes the response buffered by a timeoutWriter. Its headers
+// started as a copy of those of the response, so only the headers added by the
+// filters after the TimeoutFilter returned are kept.
+type bufferedResult struct {
+	writer *timeoutWriter
+}
+
+func (r bufferedResult) Apply(req *Request, resp *Response) {
+	header := resp.Out.Header()
+	for key, values := range r.writer.header {
+		header[key] = values
+	}
+	if r.writer.status != 0 {
+		resp.Out.WriteHeader(r.writer.status)
+	}
+	resp.Out.Write(r.writer.body.Bytes())
+}
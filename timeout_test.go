@@ -0,0 +1,120 @@
+package revel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// timeoutTester runs the TimeoutFilter for a request to Hotels.Show, and the
+// action, and applies the buffered result of the action.
+func timeoutTester(action func(c *Controller)) (*Controller, *httptest.ResponseRecorder) {
+	httpRequest, _ := http.NewRequest("GET", "/hotels/3", nil)
+	recorder := httptest.NewRecorder()
+	c := NewController(NewRequest(httpRequest), NewResponse(recorder))
+	c.Name, c.MethodName, c.Action = "Hotels", "Show", "Hotels.Show"
+	c.Response.Out.Header().Set("X-Outer", "kept")
+
+	TimeoutFilter(c, []Filter{func(c *Controller, _ []Filter) {
+		action(c)
+	}})
+	if result, ok := c.Result.(bufferedResult); ok {
+		result.Apply(c.Request, c.Response)
+	}
+	return c, recorder
+}
+
+func TestTimeoutFilter(t *testing.T) {
+	defer func(saved time.Duration) { defaultTimeout = saved }(defaultTimeout)
+	defaultTimeout = 50 * time.Millisecond
+
+	_, recorder := timeoutTester(func(c *Controller) {
+		c.Response.Out.Header().Set("X-Inner", "set")
+		c.Response.Status = http.StatusCreated
+		c.Result = c.RenderText("created")
+	})
+	if recorder.Code != http.StatusCreated || recorder.Body.String() != "created" {
+		t.Errorf("Expected the response of the action, got %d %q", recorder.Code, recorder.Body.String())
+	}
+	if recorder.Header().Get("X-Inner") != "set" || recorder.Header().Get("X-Outer") != "kept" {
+		t.Errorf("Expected the headers to be kept, got %v", recorder.Header())
+	}
+
+	var (
+		cancelled = make(chan error, 1)
+		lateWrite = make(chan error, 1)
+	)
+	c, _ := timeoutTester(func(c *Controller) {
+		<-c.Context().Done()
+		cancelled <- c.Context().Err()
+		time.Sleep(10 * time.Millisecond)
+		_, err := c.Response.Out.Write([]byte("late"))
+		lateWrite <- err
+	})
+	if c.Response.Status != http.StatusServiceUnavailable {
+		t.Errorf("Expected a 503 on timeout, got %d", c.Response.Status)
+	}
+	if _, ok := c.Result.(ErrorResult); !ok {
+		t.Errorf("Expected an ErrorResult on timeout, got %#v", c.Result)
+	}
+	if err := <-cancelled; err == nil {
+		t.Errorf("Expected the context of the action to be cancelled")
+	}
+	if err := <-lateWrite; err != http.ErrHandlerTimeout {
+		t.Errorf("Expected the late write to be discarded, got %v", err)
+	}
+}
+
+func TestTimeoutFilterCompletedAtDeadline(t *testing.T) {
+	defer func(saved time.Duration) { defaultTimeout = saved }(defaultTimeout)
+	defaultTimeout = 20 * time.Millisecond
+
+	// The response is complete when the deadline passes, before the chain
+	// returns.
+	c, recorder := timeoutTester(func(c *Controller) {
+		c.Response.Out.Write([]byte("done"))
+		c.Response.Out.(*timeoutWriter).complete()
+		<-c.Context().Done()
+	})
+	if _, ok := c.Result.(bufferedResult); !ok {
+		t.Errorf("Expected the buffered result, got %#v", c.Result)
+	}
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "done" {
+		t.Errorf("Expected the completed response, got %d %q", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestActionTimeout(t *testing.T) {
+	defer func(saved time.Duration, savedTimeouts map[string]time.Duration) {
+		defaultTimeout, actionTimeouts = saved, savedTimeouts
+	}(defaultTimeout, actionTimeouts)
+	defaultTimeout = time.Millisecond
+	actionTimeouts = make(map[string]time.Duration)
+
+	FilterConfigurator{key: "Hotels", controllerName: "Hotels"}.Timeout(time.Minute)
+	FilterConfigurator{key: "Hotels.Show", controllerName: "Hotels"}.Timeout(0)
+
+	for _, test := range []struct {
+		name, action string
+		expected     time.Duration
+	}{
+		{"Hotels", "Hotels.Show", 0},
+		{"Hotels", "Hotels.Index", time.Minute},
+		{"App", "App.Index", time.Millisecond},
+	} {
+		c := &Controller{Name: test.name, Action: test.action}
+		if timeout := actionTimeout(c); timeout != test.expected {
+			t.Errorf("Expected a timeout of %s for %s, got %s", test.expected, test.action, timeout)
+		}
+	}
+
+	// The disabled timeout runs the action directly.
+	c, _ := timeoutTester(func(c *Controller) {
+		time.Sleep(5 * time.Millisecond)
+		c.Result = c.RenderText("done")
+	})
+	if _, ok := c.Result.(*RenderTextResult); !ok {
+		t.Errorf("Expected the result of the action, got %#v", c.Result)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"reflect"
@@ -120,6 +121,25 @@ func ParseKeyValueCookie(val string, cb func(key, val string)) {
 	}
 }
 
+// NewCookie returns a cookie with the given name and value, and the
+// configured cookie attributes: HttpOnly, Secure, SameSite, Domain and Path.
+// All the cookies set by Revel are made with it.
+func NewCookie(name, value string) *http.Cookie {
+	path := CookiePath
+	if path == "" {
+		path = "/"
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   CookieDomain,
+		HttpOnly: CookieHttpOnly,
+		Secure:   CookieSecure,
+		SameSite: CookieSameSite,
+	}
+}
+
 const DefaultFileContentType = "application/octet-stream"
 
 var mimeConfig *MergedConfig
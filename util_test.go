@@ -1,6 +1,7 @@
 package revel
 
 import (
+	"net/http"
 	"path"
 	"path/filepath"
 	"reflect"
@@ -90,3 +91,22 @@ func TestEqual(t *testing.T) {
 	testRow("strings2", "strings", false)
 	testRow("strings", "strings2", false)
 }
+
+func TestNewCookie(t *testing.T) {
+	defer func() {
+		CookieSecure, CookieHttpOnly, CookieSameSite = false, false, 0
+		CookieDomain, CookiePath = "", ""
+	}()
+
+	CookieSecure, CookieHttpOnly, CookieSameSite = true, true, http.SameSiteStrictMode
+	CookieDomain, CookiePath = ".example.com", "/app"
+	cookie := NewCookie("REVEL_FLASH", "value")
+	if header := cookie.String(); header != "REVEL_FLASH=value; Path=/app; Domain=example.com; HttpOnly; Secure; SameSite=Strict" {
+		t.Errorf("Unexpected cookie: %s", header)
+	}
+
+	CookiePath = ""
+	if cookie = NewCookie("REVEL_FLASH", "value"); cookie.Path != "/" {
+		t.Errorf("Expected the path to default to /, got %s", cookie.Path)
+	}
+}
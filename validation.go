@@ -2,10 +2,13 @@ package revel
 
 import (
 	"fmt"
-	"net/http"
-	"net/url"
+	"reflect"
 	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Simple struct to store the Message & Key of a validation error
@@ -23,8 +26,10 @@ func (e *ValidationError) String() string {
 
 // A Validation context manages data validation and error messages.
 type Validation struct {
-	Errors []*ValidationError
-	keep   bool
+	Errors     []*ValidationError
+	controller *Controller
+	params     map[string]string
+	keep       bool
 }
 
 // Keep tells revel to set a flash cookie on the client to make the validation
@@ -135,14 +140,95 @@ func (v *Validation) Email(str string) *ValidationResult {
 	return v.apply(Email{Match{emailPattern}}, str)
 }
 
+func (v *Validation) MinFloat(n float64, min float64) *ValidationResult {
+	return v.apply(MinFloat{min}, n)
+}
+
+func (v *Validation) MaxFloat(n float64, max float64) *ValidationResult {
+	return v.apply(MaxFloat{max}, n)
+}
+
+func (v *Validation) RangeFloat(n, min, max float64) *ValidationResult {
+	return v.apply(RangeFloat{MinFloat{min}, MaxFloat{max}}, n)
+}
+
+func (v *Validation) MinTime(t time.Time, min time.Time) *ValidationResult {
+	return v.apply(MinTime{min}, t)
+}
+
+func (v *Validation) MaxTime(t time.Time, max time.Time) *ValidationResult {
+	return v.apply(MaxTime{max}, t)
+}
+
+func (v *Validation) RangeTime(t, min, max time.Time) *ValidationResult {
+	return v.apply(RangeTime{MinTime{min}, MaxTime{max}}, t)
+}
+
+func (v *Validation) URL(str string) *ValidationResult {
+	return v.apply(URL{}, str)
+}
+
+func (v *Validation) IPAddr(str string) *ValidationResult {
+	return v.apply(IPAddr{}, str)
+}
+
+func (v *Validation) CIDR(str string) *ValidationResult {
+	return v.apply(CIDR{}, str)
+}
+
+func (v *Validation) UUID(str string) *ValidationResult {
+	return v.apply(UUID{Match{uuidPattern}}, str)
+}
+
+func (v *Validation) Hostname(str string) *ValidationResult {
+	return v.apply(Hostname{Match{hostnamePattern}}, str)
+}
+
+func (v *Validation) Phone(str string) *ValidationResult {
+	return v.apply(Phone{Match{phonePattern}}, str)
+}
+
+func (v *Validation) CreditCard(str string) *ValidationResult {
+	return v.apply(CreditCard{}, str)
+}
+
+func (v *Validation) OneOf(obj interface{}, values ...interface{}) *ValidationResult {
+	return v.apply(OneOf{values}, obj)
+}
+
+// EqualTo checks that obj equals other, e.g. a password confirmation.
+// name describes the other value in the error message.
+func (v *Validation) EqualTo(obj, other interface{}, name string) *ValidationResult {
+	return v.apply(EqualTo{other, name}, obj)
+}
+
 func (v *Validation) apply(chk Validator, obj interface{}) *ValidationResult {
-	if chk.IsSatisfied(obj) {
+	if v.isSatisfied(chk, obj) {
 		return &ValidationResult{Ok: true}
 	}
 
-	// Get the default key.
+	return v.addError(chk, defaultValidationKey(3))
+}
+
+// isSatisfied runs the given check against obj. A ContextValidator is given
+// the current controller, and an error returned by it panics so that it is
+// reported as a server error rather than as a failed validation.
+func (v *Validation) isSatisfied(chk Validator, obj interface{}) bool {
+	if cv, ok := chk.(ContextValidator); ok {
+		ok, err := cv.IsSatisfiedContext(v.controller, obj)
+		if err != nil {
+			panic(err)
+		}
+		return ok
+	}
+	return chk.IsSatisfied(obj)
+}
+
+// defaultValidationKey looks up the name of the variable being validated by
+// the caller that is skip frames up the stack.
+func defaultValidationKey(skip int) string {
 	var key string
-	if pc, _, line, ok := runtime.Caller(2); ok {
+	if pc, _, line, ok := runtime.Caller(skip); ok {
 		f := runtime.FuncForPC(pc)
 		if defaultKeys, ok := DefaultValidationKeys[f.Name()]; ok {
 			key = defaultKeys[line]
@@ -150,10 +236,13 @@ func (v *Validation) apply(chk Validator, obj interface{}) *ValidationResult {
 	} else {
 		INFO.Println("Failed to get Caller information to look up Validation key")
 	}
+	return key
+}
 
-	// Add the error to the validation context.
+// addError adds the failure of the given check to the validation context.
+func (v *Validation) addError(chk Validator, key string) *ValidationResult {
 	err := &ValidationError{
-		Message: chk.DefaultMessage(),
+		Message: v.message(chk),
 		Key:     key,
 	}
 	v.Errors = append(v.Errors, err)
@@ -165,6 +254,19 @@ func (v *Validation) apply(chk Validator, obj interface{}) *ValidationResult {
 	}
 }
 
+// message returns the error message for the given check, translated for the
+// request locale when the validator provides a message key and the key is
+// found in the loaded messages. Otherwise, the DefaultMessage is used.
+func (v *Validation) message(chk Validator) string {
+	if lv, ok := chk.(LocalizedValidator); ok && v.controller != nil && v.controller.Request != nil {
+		key, args := lv.MessageKey()
+		if message, found := findMessage(v.controller.Request.Locale, key, args...); found {
+			return message
+		}
+	}
+	return chk.DefaultMessage()
+}
+
 // Apply a group of validators to a field, in order, and return the
 // ValidationResult from the first one that fails, or the last one that
 // succeeds.
@@ -179,68 +281,474 @@ func (v *Validation) Check(obj interface{}, checks ...Validator) *ValidationResu
 	return result
 }
 
+// Struct validates the fields of the given struct (or pointer to struct)
+// according to their `validate` tags, e.g.
+//
+//   type User struct {
+//     Name  string `validate:"required,min=3,max=50,match=^[a-z]+$"`
+//     Email string `validate:"required,email"`
+//   }
+//
+//   c.Validation.Struct(user)
+//
+// Each entry of the tag names a validator registered in ValidationTags,
+// optionally followed by "=" and its argument. The checks of a field are
+// applied in order, and only the first failure is recorded.  Since "match"
+// takes a regular expression that may contain commas, it consumes the rest of
+// the tag and must come last.
+//
+// Error keys follow the binder naming, e.g. "user.Email", so that the "field"
+// and "errorClass" template helpers pick them up.  Nested structs, slices and
+// maps of structs are validated as well.
+//
+// Returns a failed result if any field failed validation.
+func (v *Validation) Struct(obj interface{}) *ValidationResult {
+	return v.validateStruct(defaultValidationKey(2), reflect.ValueOf(obj))
+}
+
+func (v *Validation) validateStruct(name string, val reflect.Value) *ValidationResult {
+	numErrors := len(v.Errors)
+	v.validateValue(name, val, make(map[visitedValue]bool))
+	if len(v.Errors) > numErrors {
+		return &ValidationResult{Ok: false, Error: v.Errors[numErrors]}
+	}
+	return &ValidationResult{Ok: true}
+}
+
+// A visitedValue identifies a value reached through a pointer, so that
+// self-referencing structs are validated once.
+type visitedValue struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// validateValue descends into structs, slices and maps, validating every
+// tagged struct field found on the way.
+func (v *Validation) validateValue(name string, val reflect.Value, visited map[visitedValue]bool) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		if val.Kind() == reflect.Ptr {
+			key := visitedValue{val.Pointer(), val.Type()}
+			if visited[key] {
+				return
+			}
+			visited[key] = true
+		}
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		// time.Time and friends have no tagged fields, but lots of unexported ones.
+		if val.Type().PkgPath() == "time" {
+			return
+		}
+		for _, field := range structValidationFields(val.Type()) {
+			fieldValue := val.Field(field.index)
+			key := joinValidationKey(name, field.name)
+			if field.embedded {
+				// Fields of embedded structs are promoted, as in the binder.
+				key = name
+			}
+			if !v.validateField(key, field.rules, fieldValue, val) {
+				continue
+			}
+			v.validateValue(key, fieldValue, visited)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			v.validateValue(fmt.Sprintf("%s[%d]", name, i), val.Index(i), visited)
+		}
+
+	case reflect.Map:
+		for _, mapKey := range val.MapKeys() {
+			v.validateValue(fmt.Sprintf("%s[%v]", name, mapKey.Interface()), val.MapIndex(mapKey), visited)
+		}
+	}
+}
+
+// validateField applies the validators of the field, stopping at the first
+// one that fails.  Returns whether all of them succeeded.
+func (v *Validation) validateField(key string, rules []*validationRule, fieldValue, parent reflect.Value) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	obj := validationValue(fieldValue)
+	for _, rule := range rules {
+		chk := rule.validator
+		if ParentValidationTags[rule.name] {
+			var err error
+			if chk, err = rule.newValidator(rule.arg, fieldValue.Type(), parent); err != nil {
+				panic("revel/validation: invalid validate tag " + rule.entry + " on " + key + ": " + err.Error())
+			}
+		}
+
+		if !v.isSatisfied(chk, obj) {
+			v.addError(chk, key)
+			return false
+		}
+	}
+	return true
+}
+
+// A validationField is an exported field of a struct, with the validators of
+// its `validate` tag.
+type validationField struct {
+	index    int
+	name     string
+	embedded bool
+	rules    []*validationRule
+}
+
+// A validationRule is an entry of a `validate` tag, e.g. "min=3".
+type validationRule struct {
+	entry, name, arg string
+	newValidator     TagValidator
+	validator        Validator // Built for the zero value of the struct.
+}
+
+var (
+	validationFieldsMutex sync.RWMutex
+	validationFields      = make(map[reflect.Type][]validationField)
+)
+
+// structValidationFields returns the fields of the struct type to validate,
+// parsing their tags the first time the type is seen.  Unknown and invalid
+// tags panic then.
+func structValidationFields(typ reflect.Type) []validationField {
+	validationFieldsMutex.RLock()
+	fields, ok := validationFields[typ]
+	validationFieldsMutex.RUnlock()
+	if ok {
+		return fields
+	}
+
+	zero := reflect.New(typ).Elem()
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		tag := structField.Tag.Get("validate")
+		if structField.PkgPath != "" || tag == "-" {
+			continue
+		}
+		fields = append(fields, validationField{
+			index:    i,
+			name:     structField.Name,
+			embedded: structField.Anonymous,
+			rules:    parseValidateTag(typ.Name()+"."+structField.Name, tag, structField.Type, zero),
+		})
+	}
+
+	validationFieldsMutex.Lock()
+	validationFields[typ] = fields
+	validationFieldsMutex.Unlock()
+	return fields
+}
+
+// parseValidateTag builds the validators of the tag of a field.
+func parseValidateTag(field, tag string, fieldType reflect.Type, parent reflect.Value) []*validationRule {
+	var rules []*validationRule
+	for len(tag) > 0 {
+		var entry string
+		if strings.HasPrefix(tag, "match=") {
+			entry, tag = tag, ""
+		} else if comma := strings.Index(tag, ","); comma != -1 {
+			entry, tag = tag[:comma], tag[comma+1:]
+		} else {
+			entry, tag = tag, ""
+		}
+
+		name, arg := strings.TrimSpace(entry), ""
+		if eq := strings.Index(entry, "="); eq != -1 {
+			name, arg = strings.TrimSpace(entry[:eq]), entry[eq+1:]
+		}
+		if name == "" {
+			continue
+		}
+
+		newValidator, ok := ValidationTags[name]
+		if !ok {
+			panic("revel/validation: unknown validate tag " + name + " on " + field)
+		}
+		validator, err := newValidator(arg, fieldType, parent)
+		if err != nil {
+			panic("revel/validation: invalid validate tag " + entry + " on " + field + ": " + err.Error())
+		}
+		rules = append(rules, &validationRule{entry, name, arg, newValidator, validator})
+	}
+	return rules
+}
+
+// validationValue converts a field to the basic type expected by the built-in
+// validators, e.g. int64 and named string types become int and string.
+func validationValue(val reflect.Value) interface{} {
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// Values that do not fit in an int are kept as uint64, so that they
+		// are not compared as negative numbers, e.g. by Max.
+		n := val.Uint()
+		if n > uint64(^uint(0)>>1) {
+			return n
+		}
+		return int(n)
+	case reflect.String:
+		return val.String()
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		return validationValue(val.Elem())
+	}
+	return val.Interface()
+}
+
+func joinValidationKey(name, field string) string {
+	if name == "" {
+		return field
+	}
+	return name + "." + field
+}
+
+// A TagValidator builds the Validator for an entry of a `validate` struct tag,
+// given the entry's argument (the text after "="), the type of the field and
+// the struct value that contains it.
+type TagValidator func(arg string, fieldType reflect.Type, parent reflect.Value) (Validator, error)
+
+// ValidationTags maps the names used in `validate` struct tags to the
+// validators they create.  Applications may register their own.  The tags of
+// a struct type are parsed once, and their validators built for the zero
+// value of the struct, unless they are listed in ParentValidationTags.
+//
+// Built in: required, min, max, range, minsize, maxsize, length, email, match,
+// url, ip, cidr, uuid, hostname, phone, creditcard, oneof and eqfield.
+var ValidationTags = map[string]TagValidator{
+	"required": func(_ string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		return Required{}, nil
+	},
+	// min and max limit the value of numbers, and the length of strings and slices.
+	"min": func(arg string, fieldType reflect.Type, _ reflect.Value) (Validator, error) {
+		if isFloatKind(fieldType) {
+			min, err := strconv.ParseFloat(arg, 64)
+			return MinFloat{min}, err
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		if isSizedKind(fieldType) {
+			return MinSize{n}, nil
+		}
+		return Min{n}, nil
+	},
+	"max": func(arg string, fieldType reflect.Type, _ reflect.Value) (Validator, error) {
+		if isFloatKind(fieldType) {
+			max, err := strconv.ParseFloat(arg, 64)
+			return MaxFloat{max}, err
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		if isSizedKind(fieldType) {
+			return MaxSize{n}, nil
+		}
+		return Max{n}, nil
+	},
+	// e.g. range=1:10
+	"range": func(arg string, fieldType reflect.Type, _ reflect.Value) (Validator, error) {
+		bounds := strings.SplitN(arg, ":", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("expected range=min:max")
+		}
+		if isFloatKind(fieldType) {
+			min, err := strconv.ParseFloat(bounds[0], 64)
+			if err != nil {
+				return nil, err
+			}
+			max, err := strconv.ParseFloat(bounds[1], 64)
+			return RangeFloat{MinFloat{min}, MaxFloat{max}}, err
+		}
+		min, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		max, err := strconv.Atoi(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		return Range{Min{min}, Max{max}}, nil
+	},
+	"minsize": func(arg string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		n, err := strconv.Atoi(arg)
+		return MinSize{n}, err
+	},
+	"maxsize": func(arg string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		n, err := strconv.Atoi(arg)
+		return MaxSize{n}, err
+	},
+	"length": func(arg string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		n, err := strconv.Atoi(arg)
+		return Length{n}, err
+	},
+	"email": func(_ string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		return ValidEmail(), nil
+	},
+	"match": func(arg string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		regex, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return Match{regex}, nil
+	},
+	"url": func(_ string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		return URL{}, nil
+	},
+	"ip": func(_ string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		return IPAddr{}, nil
+	},
+	"cidr": func(_ string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		return CIDR{}, nil
+	},
+	"uuid": func(_ string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		return ValidUUID(), nil
+	},
+	"hostname": func(_ string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		return ValidHostname(), nil
+	},
+	"phone": func(_ string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		return ValidPhone(), nil
+	},
+	"creditcard": func(_ string, _ reflect.Type, _ reflect.Value) (Validator, error) {
+		return CreditCard{}, nil
+	},
+	// e.g. oneof=red|green|blue
+	"oneof": func(arg string, fieldType reflect.Type, _ reflect.Value) (Validator, error) {
+		var values []interface{}
+		for _, value := range strings.Split(arg, "|") {
+			converted, err := tagValue(value, fieldType)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, converted)
+		}
+		return OneOf{values}, nil
+	},
+	// e.g. eqfield=Password
+	"eqfield": func(arg string, _ reflect.Type, parent reflect.Value) (Validator, error) {
+		other := lookupFieldByName(parent, arg)
+		if !other.IsValid() {
+			// The field may be promoted from a nil embedded pointer.
+			structField, ok := structFieldByName(parent.Type(), arg)
+			if !ok {
+				return nil, fmt.Errorf("no field %s", arg)
+			}
+			other = reflect.Zero(structField.Type)
+		}
+		return EqualTo{validationValue(other), arg}, nil
+	},
+}
+
+// ParentValidationTags are the tags whose validators depend on the values of
+// the other fields of the struct, e.g. eqfield.  They are built again for
+// each validated struct.
+var ParentValidationTags = map[string]bool{
+	"eqfield": true,
+}
+
+// tagValue converts a value of a tag to the type of the field, as it is passed
+// to the validators.  Values of other types than numbers, booleans and
+// strings are kept as strings.
+func tagValue(value string, fieldType reflect.Type) (interface{}, error) {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	val := reflect.New(fieldType).Elem()
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fieldType.Bits())
+		if err != nil {
+			return nil, err
+		}
+		val.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fieldType.Bits())
+		if err != nil {
+			return nil, err
+		}
+		val.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fieldType.Bits())
+		if err != nil {
+			return nil, err
+		}
+		val.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		val.SetBool(b)
+	case reflect.String:
+		val.SetString(value)
+	default:
+		return value, nil
+	}
+	return validationValue(val), nil
+}
+
+func isFloatKind(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64
+}
+
+func isSizedKind(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Slice:
+		return true
+	}
+	return false
+}
+
 // Revel Filter function to be hooked into the filter chain.
 func ValidationFilter(c *Controller, fc []Filter) {
-	errors, err := restoreValidationErrors(c.Request.Request)
+	store := currentValidationStore()
+	errors, params := store.Restore(c)
 	c.Validation = &Validation{
-		Errors: errors,
-		keep:   false,
+		Errors:     errors,
+		controller: c,
+		keep:       false,
+	}
+
+	// Make the restored params available with the rest of the flash.
+	for key, value := range params {
+		c.Flash.Data[key] = value
 	}
-	hasCookie := (err != http.ErrNoCookie)
 
 	fc[0](c, fc[1:])
 
 	// Add Validation errors to RenderArgs.
 	c.RenderArgs["errors"] = c.Validation.ErrorMap()
 
-	// Store the Validation errors
-	var errorsValue string
+	// Store the Validation errors, if Keep() has been called, along with the
+	// flashed params.
+	var kept []*ValidationError
 	if c.Validation.keep {
 		for _, error := range c.Validation.Errors {
 			if error.Message != "" {
-				errorsValue += "\x00" + error.Key + ":" + error.Message + "\x00"
+				kept = append(kept, error)
 			}
 		}
 	}
-
-	// When there are errors from Validation and Keep() has been called, store the
-	// values in a cookie. If there previously was a cookie but no errors, remove
-	// the cookie.
-	if errorsValue != "" {
-		c.SetCookie(&http.Cookie{
-			Name:     CookiePrefix + "_ERRORS",
-			Value:    url.QueryEscape(errorsValue),
-			Path:     "/",
-			HttpOnly: CookieHttpOnly,
-			Secure:   CookieSecure,
-		})
-	} else if hasCookie {
-		c.SetCookie(&http.Cookie{
-			Name:     CookiePrefix + "_ERRORS",
-			MaxAge:   -1,
-			Path:     "/",
-			HttpOnly: CookieHttpOnly,
-			Secure:   CookieSecure,
-		})
-	}
-}
-
-// Restore Validation.Errors from a request.
-func restoreValidationErrors(req *http.Request) ([]*ValidationError, error) {
-	var (
-		err    error
-		cookie *http.Cookie
-		errors = make([]*ValidationError, 0, 5)
-	)
-	if cookie, err = req.Cookie(CookiePrefix + "_ERRORS"); err == nil {
-		ParseKeyValueCookie(cookie.Value, func(key, val string) {
-			errors = append(errors, &ValidationError{
-				Key:     key,
-				Message: val,
-			})
-		})
-	}
-	return errors, err
+	store.Store(c, kept, c.Validation.params)
 }
 
 // Register default validation keys for all calls to Controller.Validation.Func().
@@ -0,0 +1,147 @@
+package revel
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// A ValidationStore keeps the validation errors saved with Validation.Keep,
+// and the params saved with Controller.FlashParams, until the next request.
+//
+// The store is selected with "validation.store" in app.conf. Revel provides
+// "cookie" (the default), and the cache package registers "cache". Server
+// side stores are keyed by the session id, so they are not limited by the
+// size of a cookie.
+type ValidationStore interface {
+	// Restore returns the errors and params stored by the previous request.
+	Restore(c *Controller) ([]*ValidationError, map[string]string)
+
+	// Store saves the errors and params for the next request. It is called
+	// after every request, with nothing to keep if neither Keep nor
+	// FlashParams were called, and must clear what was restored.
+	Store(c *Controller, errors []*ValidationError, params map[string]string)
+}
+
+// A ValidationCache is the part of a cache.Cache used by CacheValidationStore.
+type ValidationCache interface {
+	Get(key string, ptrValue interface{}) error
+	Set(key string, value interface{}, expires time.Duration) error
+	Delete(key string) error
+}
+
+var (
+	validationStores = map[string]ValidationStore{
+		"cookie": CookieValidationStore{},
+	}
+
+	validationStoreName = "cookie"
+)
+
+func init() {
+	OnAppStart(func() {
+		validationStoreName = Config.StringDefault("validation.store", "cookie")
+	})
+}
+
+// RegisterValidationStore makes a ValidationStore available under the given
+// name for use in "validation.store".
+func RegisterValidationStore(name string, store ValidationStore) {
+	validationStores[name] = store
+}
+
+// currentValidationStore returns the store selected in the configuration.
+func currentValidationStore() ValidationStore {
+	if store, ok := validationStores[validationStoreName]; ok {
+		return store
+	}
+	WARN.Printf("Unknown validation.store %s, using cookie", validationStoreName)
+	return validationStores["cookie"]
+}
+
+// CookieValidationStore keeps the errors in the _ERRORS cookie, and the params
+// in the Flash cookie.
+type CookieValidationStore struct{}
+
+func (s CookieValidationStore) Restore(c *Controller) ([]*ValidationError, map[string]string) {
+	errors := make([]*ValidationError, 0, 5)
+	if cookie, err := c.Request.Cookie(CookiePrefix + "_ERRORS"); err == nil {
+		ParseKeyValueCookie(cookie.Value, func(key, val string) {
+			errors = append(errors, &ValidationError{
+				Key:     key,
+				Message: val,
+			})
+		})
+	}
+
+	// The params come back with the Flash cookie.
+	return errors, nil
+}
+
+func (s CookieValidationStore) Store(c *Controller, errors []*ValidationError, params map[string]string) {
+	for key, value := range params {
+		c.Flash.Out[key] = value
+	}
+
+	var errorsValue string
+	for _, error := range errors {
+		errorsValue += "\x00" + error.Key + ":" + error.Message + "\x00"
+	}
+
+	// When there are errors to keep, store the values in a cookie. If there
+	// previously was a cookie but no errors, remove the cookie.
+	if errorsValue != "" {
+		c.SetCookie(NewCookie(CookiePrefix+"_ERRORS", url.QueryEscape(errorsValue)))
+	} else if _, err := c.Request.Cookie(CookiePrefix + "_ERRORS"); err != http.ErrNoCookie {
+		cookie := NewCookie(CookiePrefix+"_ERRORS", "")
+		cookie.MaxAge = -1
+		c.SetCookie(cookie)
+	}
+}
+
+// CacheValidationStore keeps the errors and params in a cache, keyed by the
+// session id.
+type CacheValidationStore struct {
+	Cache   ValidationCache
+	Expires time.Duration
+}
+
+// The value saved in the cache by CacheValidationStore.
+type storedValidation struct {
+	Errors []*ValidationError
+	Params map[string]string
+}
+
+func NewCacheValidationStore(cache ValidationCache, expires time.Duration) CacheValidationStore {
+	return CacheValidationStore{cache, expires}
+}
+
+func (s CacheValidationStore) Restore(c *Controller) ([]*ValidationError, map[string]string) {
+	// Without a session id, nothing can have been stored.
+	sid, ok := c.Session[SESSION_ID_KEY]
+	if !ok {
+		return nil, nil
+	}
+
+	var stored storedValidation
+	if err := s.Cache.Get(validationCacheKey(sid), &stored); err != nil {
+		return nil, nil
+	}
+	s.Cache.Delete(validationCacheKey(sid))
+	return stored.Errors, stored.Params
+}
+
+func (s CacheValidationStore) Store(c *Controller, errors []*ValidationError, params map[string]string) {
+	if len(errors) == 0 && len(params) == 0 {
+		return
+	}
+
+	stored := storedValidation{errors, params}
+	if err := s.Cache.Set(validationCacheKey(c.Session.Id()), stored, s.Expires); err != nil {
+		ERROR.Println("Failed to store validation errors:", err)
+	}
+}
+
+func validationCacheKey(sid string) string {
+	return "revel.validation:" + sid
+}
@@ -1,9 +1,13 @@
 package revel
 
 import (
+	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 )
 
 // getRecordedCookie returns the recorded cookie from a ResponseRecorder with
@@ -74,3 +78,293 @@ func TestValidationNoKeepCookiePreviouslySet(t *testing.T) {
 		t.Fatalf("cookie should be deleted")
 	}
 }
+
+type ValidatedAddress struct {
+	City string `validate:"required"`
+}
+
+type validatedUser struct {
+	ValidatedAddress
+	Name     string `validate:"required,min=3,max=10,match=^[a-z]{3,10}$"`
+	Email    string `validate:"required,email"`
+	Age      int64  `validate:"range=18:99"`
+	Tags     []string
+	Address  *ValidatedAddress
+	Previous []ValidatedAddress
+	Ignored  string `validate:"-"`
+}
+
+func TestValidationStruct(t *testing.T) {
+	user := validatedUser{
+		Name:     "ROB",
+		Email:    "rob@example.com",
+		Age:      12,
+		Address:  &ValidatedAddress{},
+		Previous: []ValidatedAddress{{City: "Paris"}, {}},
+	}
+
+	v := &Validation{}
+	if result := v.validateStruct("user", reflect.ValueOf(&user)); result.Ok {
+		t.Fatal("expected the struct to fail validation")
+	}
+
+	expected := map[string]string{
+		"user.City":             "Required",
+		"user.Name":             fmt.Sprintln("Must match", "^[a-z]{3,10}$"),
+		"user.Age":              fmt.Sprintln("Range is", 18, "to", 99),
+		"user.Address.City":     "Required",
+		"user.Previous[1].City": "Required",
+	}
+	errors := v.ErrorMap()
+	if len(errors) != len(expected) {
+		t.Errorf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for key, message := range expected {
+		if err, ok := errors[key]; !ok {
+			t.Errorf("Expected an error for %s", key)
+		} else if err.Message != message {
+			t.Errorf("Error for %s: (expected) %q != %q (actual)", key, message, err.Message)
+		}
+	}
+
+	// Without a known variable name, the keys are the field paths.
+	v = &Validation{}
+	user = validatedUser{ValidatedAddress{"Paris"}, "rob", "rob@example.com", 30, nil, nil, nil, ""}
+	if result := v.Struct(user); !result.Ok {
+		t.Errorf("expected the struct to pass validation: %v", v.ErrorMap())
+	}
+}
+
+type PasswordChange struct {
+	Password     string  `validate:"required,min=8"`
+	Confirmation string  `validate:"eqfield=Password"`
+	Color        string  `validate:"oneof=red|green"`
+	Weight       float64 `validate:"range=0.5:2.5"`
+	Size         int     `validate:"oneof=1|2"`
+	Ratio        float32 `validate:"oneof=0.5|1.5"`
+}
+
+func TestValidationStructRichTags(t *testing.T) {
+	v := &Validation{}
+	v.validateStruct("change", reflect.ValueOf(PasswordChange{"password", "passw0rd", "blue", 3, 3, 2}))
+
+	errors := v.ErrorMap()
+	for _, key := range []string{"change.Confirmation", "change.Color", "change.Weight", "change.Size", "change.Ratio"} {
+		if _, ok := errors[key]; !ok {
+			t.Errorf("Expected an error for %s", key)
+		}
+	}
+	if _, ok := errors["change.Password"]; ok {
+		t.Errorf("Unexpected error for change.Password")
+	}
+
+	// Numeric values of oneof are compared as numbers.
+	v = &Validation{}
+	v.validateStruct("change", reflect.ValueOf(PasswordChange{"password", "password", "red", 1, 2, 1.5}))
+	if v.HasErrors() {
+		t.Errorf("Expected the change to pass validation, got %v", v.ErrorMap())
+	}
+}
+
+type embeddedPassword struct {
+	Password string
+}
+
+type passwordConfirmation struct {
+	*embeddedPassword
+	Confirmation string `validate:"eqfield=Password"`
+}
+
+func TestValidationEqFieldNilEmbedded(t *testing.T) {
+	confirmation := &passwordConfirmation{Confirmation: "password"}
+	v := &Validation{}
+	v.validateStruct("confirmation", reflect.ValueOf(confirmation))
+	if _, ok := v.ErrorMap()["confirmation.Confirmation"]; !ok {
+		t.Errorf("Expected the confirmation not to equal a missing password")
+	}
+	if confirmation.embeddedPassword != nil {
+		t.Errorf("Expected the embedded pointer to be left nil")
+	}
+}
+
+type validatedCounter struct {
+	Count uint64 `validate:"range=0:100"`
+}
+
+func TestValidationLargeUint(t *testing.T) {
+	v := &Validation{}
+	v.validateStruct("counter", reflect.ValueOf(validatedCounter{math.MaxUint64}))
+	if _, ok := v.ErrorMap()["counter.Count"]; !ok {
+		t.Errorf("Expected a uint64 beyond the int range to fail the range")
+	}
+
+	v = &Validation{}
+	v.validateStruct("counter", reflect.ValueOf(validatedCounter{100}))
+	if v.HasErrors() {
+		t.Errorf("Expected the counter to pass validation, got %v", v.ErrorMap())
+	}
+	if !(Min{5}).IsSatisfied(validationValue(reflect.ValueOf(uint64(math.MaxUint64)))) {
+		t.Errorf("Expected a uint64 beyond the int range to satisfy a minimum")
+	}
+}
+
+type validatedNode struct {
+	Name   string `validate:"required"`
+	Parent *validatedNode
+}
+
+type invalidlyTagged struct {
+	Name string `validate:"unknowntag"`
+}
+
+func TestValidationStructCache(t *testing.T) {
+	// Self-referencing structs are validated once.
+	node := &validatedNode{}
+	node.Parent = node
+	v := &Validation{}
+	if result := v.validateStruct("node", reflect.ValueOf(node)); result.Ok || len(v.Errors) != 1 {
+		t.Errorf("Expected a single error for the cycle, got %v", v.ErrorMap())
+	}
+
+	// The validators of a type are built once.
+	fields := structValidationFields(reflect.TypeOf(validatedUser{}))
+	again := structValidationFields(reflect.TypeOf(validatedUser{}))
+	if len(fields) == 0 || len(fields[1].rules) != 4 || fields[1].rules[3] != again[1].rules[3] {
+		t.Errorf("Expected the rules of validatedUser to be cached, got %v", fields)
+	}
+
+	// Unknown tags panic on the first use of the type.
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("Expected an unknown validate tag to panic")
+		}
+	}()
+	structValidationFields(reflect.TypeOf(invalidlyTagged{}))
+}
+
+func TestValidationLocalizedMessages(t *testing.T) {
+	loadMessages(messageFilesDirectory, testDataPath)
+	loadTestI18nConfig(t)
+	defer loadMessages(testDataPath)
+
+	for locale, expected := range map[string]string{
+		"nl":    "Minimale lengte is 3",
+		"en":    "Minimum size is 3",
+		"en-AU": "Minimum size is 3",
+	} {
+		v := &Validation{controller: &Controller{Request: &Request{Locale: locale}}}
+		if result := v.MinSize("ab", 3); result.Error.Message != expected {
+			t.Errorf("Expected message %q for locale %s, got %q", expected, locale, result.Error.Message)
+		}
+	}
+
+	// Without a matching message, the validator's default message is used.
+	v := &Validation{controller: &Controller{Request: &Request{Locale: "nl"}}}
+	if result := v.Required(""); result.Error.Message != (Required{}).DefaultMessage() {
+		t.Errorf("Expected the default message, got %q", result.Error.Message)
+	}
+}
+
+func TestValidationContextValidator(t *testing.T) {
+	taken := map[string]bool{"robfig": true}
+	controller := &Controller{Request: &Request{}}
+	unique := ValidContext("Is already taken", func(c *Controller, obj interface{}) (bool, error) {
+		if c != controller {
+			t.Errorf("Expected the validation controller, got %v", c)
+		}
+		return !taken[obj.(string)], nil
+	})
+
+	v := &Validation{controller: controller}
+	if result := v.Check("newbie", unique); !result.Ok {
+		t.Errorf("Expected newbie to be available")
+	}
+	if result := v.Check("robfig", ValidRequired(), unique); result.Ok || result.Error.Message != "Is already taken" {
+		t.Errorf("Expected robfig to be taken, got %#v", result)
+	}
+
+	// An error from the validator itself is not a validation failure.
+	failing := ValidContext("Is already taken", func(c *Controller, obj interface{}) (bool, error) {
+		return false, fmt.Errorf("database is down")
+	})
+	defer func() {
+		if err := recover(); err == nil {
+			t.Errorf("Expected a panic for a failing validator")
+		}
+		if len(v.Errors) != 1 {
+			t.Errorf("Expected only the earlier error to be recorded, got %d", len(v.Errors))
+		}
+	}()
+	v.Check("newbie", failing)
+}
+
+// mapValidationCache is a ValidationCache for testing.
+type mapValidationCache map[string]storedValidation
+
+func (m mapValidationCache) Get(key string, ptrValue interface{}) error {
+	value, ok := m[key]
+	if !ok {
+		return fmt.Errorf("cache miss")
+	}
+	*ptrValue.(*storedValidation) = value
+	return nil
+}
+
+func (m mapValidationCache) Set(key string, value interface{}, expires time.Duration) error {
+	m[key] = value.(storedValidation)
+	return nil
+}
+
+func (m mapValidationCache) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+// Test that kept errors and flashed params round trip through a server side
+// store, keyed by session id, without any cookie.
+func TestValidationCacheStore(t *testing.T) {
+	cache := mapValidationCache{}
+	RegisterValidationStore("test", NewCacheValidationStore(cache, time.Minute))
+	validationStoreName = "test"
+	defer func() { validationStoreName = "cookie" }()
+
+	session := make(Session)
+	storeTester := func(fn func(c *Controller)) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		c := NewController(buildEmptyRequest(), NewResponse(recorder))
+		c.Session = session
+		c.Flash = Flash{Data: map[string]string{}, Out: map[string]string{}}
+		c.Params = &Params{Values: map[string][]string{"name": {"x"}}}
+		ValidationFilter(c, []Filter{func(c *Controller, _ []Filter) {
+			fn(c)
+		}})
+		return recorder
+	}
+
+	recorder := storeTester(func(c *Controller) {
+		c.Validation.Required("")
+		c.Validation.Keep()
+		c.FlashParams()
+		if len(c.Flash.Out) != 0 {
+			t.Errorf("Expected no params in the flash cookie, got %v", c.Flash.Out)
+		}
+	})
+	if _, err := getRecordedCookie(recorder, "REVEL_ERRORS"); err != http.ErrNoCookie {
+		t.Errorf("Expected no errors cookie")
+	}
+	if len(cache) != 1 {
+		t.Fatalf("Expected the errors to be stored for the session, got %v", cache)
+	}
+
+	storeTester(func(c *Controller) {
+		if len(c.Validation.Errors) != 1 {
+			t.Errorf("Expected the kept error to be restored, got %v", c.Validation.Errors)
+		}
+		if c.Flash.Data["name"] != "x" {
+			t.Errorf("Expected the flashed param to be restored, got %v", c.Flash.Data)
+		}
+	})
+	if len(cache) != 0 {
+		t.Errorf("Expected the restored errors to be removed, got %v", cache)
+	}
+}
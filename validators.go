@@ -2,8 +2,11 @@ package revel
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"reflect"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -12,6 +15,26 @@ type Validator interface {
 	DefaultMessage() string
 }
 
+// A LocalizedValidator provides the i18n message key, and its arguments, for
+// a localized version of its DefaultMessage.
+// All of the built-in validators implement it. Their English messages are
+// shipped in Revel's messages/validation.en, and may be overridden or
+// translated in the application's messages files.
+type LocalizedValidator interface {
+	Validator
+	MessageKey() (key string, args []interface{})
+}
+
+// A ContextValidator is a Validator that needs the current request to decide,
+// e.g. to check against the database that a user name is not taken yet.
+// Validation runs it with IsSatisfiedContext, passing the controller (which
+// is nil for a Validation created outside of the filter chain). An error
+// returned from it results in a 500 rather than a failed validation.
+type ContextValidator interface {
+	Validator
+	IsSatisfiedContext(c *Controller, obj interface{}) (bool, error)
+}
+
 type Required struct{}
 
 func ValidRequired() Required {
@@ -46,6 +69,10 @@ func (r Required) DefaultMessage() string {
 	return "Required"
 }
 
+func (r Required) MessageKey() (string, []interface{}) {
+	return "validation.required", nil
+}
+
 type Min struct {
 	Min int
 }
@@ -55,9 +82,12 @@ func ValidMin(min int) Min {
 }
 
 func (m Min) IsSatisfied(obj interface{}) bool {
-	num, ok := obj.(int)
-	if ok {
+	switch num := obj.(type) {
+	case int:
 		return num >= m.Min
+	case uint64:
+		// Larger than any int, see validationValue.
+		return m.Min <= 0 || num >= uint64(m.Min)
 	}
 	return false
 }
@@ -66,6 +96,10 @@ func (m Min) DefaultMessage() string {
 	return fmt.Sprintln("Minimum is", m.Min)
 }
 
+func (m Min) MessageKey() (string, []interface{}) {
+	return "validation.min", []interface{}{m.Min}
+}
+
 type Max struct {
 	Max int
 }
@@ -75,9 +109,11 @@ func ValidMax(max int) Max {
 }
 
 func (m Max) IsSatisfied(obj interface{}) bool {
-	num, ok := obj.(int)
-	if ok {
+	switch num := obj.(type) {
+	case int:
 		return num <= m.Max
+	case uint64:
+		return m.Max >= 0 && num <= uint64(m.Max)
 	}
 	return false
 }
@@ -86,6 +122,10 @@ func (m Max) DefaultMessage() string {
 	return fmt.Sprintln("Maximum is", m.Max)
 }
 
+func (m Max) MessageKey() (string, []interface{}) {
+	return "validation.max", []interface{}{m.Max}
+}
+
 // Requires an integer to be within Min, Max inclusive.
 type Range struct {
 	Min
@@ -104,6 +144,10 @@ func (r Range) DefaultMessage() string {
 	return fmt.Sprintln("Range is", r.Min.Min, "to", r.Max.Max)
 }
 
+func (r Range) MessageKey() (string, []interface{}) {
+	return "validation.range", []interface{}{r.Min.Min, r.Max.Max}
+}
+
 // Requires an array or string to be at least a given length.
 type MinSize struct {
 	Min int
@@ -128,6 +172,10 @@ func (m MinSize) DefaultMessage() string {
 	return fmt.Sprintln("Minimum size is", m.Min)
 }
 
+func (m MinSize) MessageKey() (string, []interface{}) {
+	return "validation.min_size", []interface{}{m.Min}
+}
+
 // Requires an array or string to be at most a given length.
 type MaxSize struct {
 	Max int
@@ -152,6 +200,10 @@ func (m MaxSize) DefaultMessage() string {
 	return fmt.Sprintln("Maximum size is", m.Max)
 }
 
+func (m MaxSize) MessageKey() (string, []interface{}) {
+	return "validation.max_size", []interface{}{m.Max}
+}
+
 // Requires an array or string to be exactly a given length.
 type Length struct {
 	N int
@@ -176,6 +228,10 @@ func (s Length) DefaultMessage() string {
 	return fmt.Sprintln("Required length is", s.N)
 }
 
+func (s Length) MessageKey() (string, []interface{}) {
+	return "validation.length", []interface{}{s.N}
+}
+
 // Requires a string to match a given regex.
 type Match struct {
 	Regexp *regexp.Regexp
@@ -186,14 +242,18 @@ func ValidMatch(regex *regexp.Regexp) Match {
 }
 
 func (m Match) IsSatisfied(obj interface{}) bool {
-	str := obj.(string)
-	return m.Regexp.MatchString(str)
+	str, ok := obj.(string)
+	return ok && m.Regexp.MatchString(str)
 }
 
 func (m Match) DefaultMessage() string {
 	return fmt.Sprintln("Must match", m.Regexp)
 }
 
+func (m Match) MessageKey() (string, []interface{}) {
+	return "validation.match", []interface{}{m.Regexp.String()}
+}
+
 var emailPattern = regexp.MustCompile("^[\\w!#$%&'*+/=?^_`{|}~-]+(?:\\.[\\w!#$%&'*+/=?^_`{|}~-]+)*@(?:[\\w](?:[\\w-]*[\\w])?\\.)+[a-zA-Z0-9](?:[\\w-]*[\\w])?$")
 
 type Email struct {
@@ -207,3 +267,423 @@ func ValidEmail() Email {
 func (e Email) DefaultMessage() string {
 	return fmt.Sprintln("Must be a valid email address")
 }
+
+func (e Email) MessageKey() (string, []interface{}) {
+	return "validation.email", nil
+}
+
+// toFloat converts any integer or float value to a float64.
+func toFloat(obj interface{}) (float64, bool) {
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// Requires a number to be at least a given float.
+type MinFloat struct {
+	Min float64
+}
+
+func ValidMinFloat(min float64) MinFloat {
+	return MinFloat{min}
+}
+
+func (m MinFloat) IsSatisfied(obj interface{}) bool {
+	num, ok := toFloat(obj)
+	return ok && num >= m.Min
+}
+
+func (m MinFloat) DefaultMessage() string {
+	return fmt.Sprintln("Minimum is", m.Min)
+}
+
+func (m MinFloat) MessageKey() (string, []interface{}) {
+	return "validation.min", []interface{}{m.Min}
+}
+
+// Requires a number to be at most a given float.
+type MaxFloat struct {
+	Max float64
+}
+
+func ValidMaxFloat(max float64) MaxFloat {
+	return MaxFloat{max}
+}
+
+func (m MaxFloat) IsSatisfied(obj interface{}) bool {
+	num, ok := toFloat(obj)
+	return ok && num <= m.Max
+}
+
+func (m MaxFloat) DefaultMessage() string {
+	return fmt.Sprintln("Maximum is", m.Max)
+}
+
+func (m MaxFloat) MessageKey() (string, []interface{}) {
+	return "validation.max", []interface{}{m.Max}
+}
+
+// Requires a number to be within Min, Max inclusive.
+type RangeFloat struct {
+	MinFloat
+	MaxFloat
+}
+
+func ValidRangeFloat(min, max float64) RangeFloat {
+	return RangeFloat{MinFloat{min}, MaxFloat{max}}
+}
+
+func (r RangeFloat) IsSatisfied(obj interface{}) bool {
+	return r.MinFloat.IsSatisfied(obj) && r.MaxFloat.IsSatisfied(obj)
+}
+
+func (r RangeFloat) DefaultMessage() string {
+	return fmt.Sprintln("Range is", r.MinFloat.Min, "to", r.MaxFloat.Max)
+}
+
+func (r RangeFloat) MessageKey() (string, []interface{}) {
+	return "validation.range", []interface{}{r.MinFloat.Min, r.MaxFloat.Max}
+}
+
+// Requires a time.Time to be no earlier than a given time.
+type MinTime struct {
+	Min time.Time
+}
+
+func ValidMinTime(min time.Time) MinTime {
+	return MinTime{min}
+}
+
+func (m MinTime) IsSatisfied(obj interface{}) bool {
+	t, ok := obj.(time.Time)
+	return ok && !t.Before(m.Min)
+}
+
+func (m MinTime) DefaultMessage() string {
+	return fmt.Sprintln("Must be no earlier than", m.Min.Format(DateTimeFormat))
+}
+
+func (m MinTime) MessageKey() (string, []interface{}) {
+	return "validation.min_time", []interface{}{m.Min.Format(DateTimeFormat)}
+}
+
+// Requires a time.Time to be no later than a given time.
+type MaxTime struct {
+	Max time.Time
+}
+
+func ValidMaxTime(max time.Time) MaxTime {
+	return MaxTime{max}
+}
+
+func (m MaxTime) IsSatisfied(obj interface{}) bool {
+	t, ok := obj.(time.Time)
+	return ok && !t.After(m.Max)
+}
+
+func (m MaxTime) DefaultMessage() string {
+	return fmt.Sprintln("Must be no later than", m.Max.Format(DateTimeFormat))
+}
+
+func (m MaxTime) MessageKey() (string, []interface{}) {
+	return "validation.max_time", []interface{}{m.Max.Format(DateTimeFormat)}
+}
+
+// Requires a time.Time to be within Min, Max inclusive.
+type RangeTime struct {
+	MinTime
+	MaxTime
+}
+
+func ValidRangeTime(min, max time.Time) RangeTime {
+	return RangeTime{MinTime{min}, MaxTime{max}}
+}
+
+func (r RangeTime) IsSatisfied(obj interface{}) bool {
+	return r.MinTime.IsSatisfied(obj) && r.MaxTime.IsSatisfied(obj)
+}
+
+func (r RangeTime) DefaultMessage() string {
+	return fmt.Sprintln("Must be between", r.MinTime.Min.Format(DateTimeFormat),
+		"and", r.MaxTime.Max.Format(DateTimeFormat))
+}
+
+func (r RangeTime) MessageKey() (string, []interface{}) {
+	return "validation.range_time",
+		[]interface{}{r.MinTime.Min.Format(DateTimeFormat), r.MaxTime.Max.Format(DateTimeFormat)}
+}
+
+// Requires a string to be an absolute URL, e.g. http://example.com/path
+type URL struct{}
+
+func ValidURL() URL {
+	return URL{}
+}
+
+func (u URL) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+	parsed, err := url.Parse(str)
+	return err == nil && parsed.Scheme != "" && parsed.Host != ""
+}
+
+func (u URL) DefaultMessage() string {
+	return fmt.Sprintln("Must be a valid URL")
+}
+
+func (u URL) MessageKey() (string, []interface{}) {
+	return "validation.url", nil
+}
+
+// Requires a string to be an IPv4 or IPv6 address.
+type IPAddr struct{}
+
+func ValidIPAddr() IPAddr {
+	return IPAddr{}
+}
+
+func (i IPAddr) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	return ok && net.ParseIP(str) != nil
+}
+
+func (i IPAddr) DefaultMessage() string {
+	return fmt.Sprintln("Must be a valid IP address")
+}
+
+func (i IPAddr) MessageKey() (string, []interface{}) {
+	return "validation.ip", nil
+}
+
+// Requires a string to be an IP network in CIDR notation, e.g. 10.0.0.0/8
+type CIDR struct{}
+
+func ValidCIDR() CIDR {
+	return CIDR{}
+}
+
+func (c CIDR) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+	_, _, err := net.ParseCIDR(str)
+	return err == nil
+}
+
+func (c CIDR) DefaultMessage() string {
+	return fmt.Sprintln("Must be a valid CIDR network")
+}
+
+func (c CIDR) MessageKey() (string, []interface{}) {
+	return "validation.cidr", nil
+}
+
+var uuidPattern = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// Requires a string to be a UUID, e.g. 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+type UUID struct {
+	Match
+}
+
+func ValidUUID() UUID {
+	return UUID{Match{uuidPattern}}
+}
+
+func (u UUID) DefaultMessage() string {
+	return fmt.Sprintln("Must be a valid UUID")
+}
+
+func (u UUID) MessageKey() (string, []interface{}) {
+	return "validation.uuid", nil
+}
+
+var hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*\.?$`)
+
+// Requires a string to be a valid (RFC 1123) host name.
+type Hostname struct {
+	Match
+}
+
+func ValidHostname() Hostname {
+	return Hostname{Match{hostnamePattern}}
+}
+
+func (h Hostname) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	return ok && len(str) <= 253 && h.Match.IsSatisfied(str)
+}
+
+func (h Hostname) DefaultMessage() string {
+	return fmt.Sprintln("Must be a valid host name")
+}
+
+func (h Hostname) MessageKey() (string, []interface{}) {
+	return "validation.hostname", nil
+}
+
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// Requires a string to be a phone number in E.164 format, e.g. +14155552671
+type Phone struct {
+	Match
+}
+
+func ValidPhone() Phone {
+	return Phone{Match{phonePattern}}
+}
+
+func (p Phone) DefaultMessage() string {
+	return fmt.Sprintln("Must be a valid phone number")
+}
+
+func (p Phone) MessageKey() (string, []interface{}) {
+	return "validation.phone", nil
+}
+
+// Requires a string to be a credit card number passing the Luhn checksum.
+// Spaces and dashes between the digits are allowed.
+type CreditCard struct{}
+
+func ValidCreditCard() CreditCard {
+	return CreditCard{}
+}
+
+func (c CreditCard) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	var (
+		sum    int
+		digits int
+	)
+	for i := len(str) - 1; i >= 0; i-- {
+		ch := str[i]
+		if ch == ' ' || ch == '-' {
+			continue
+		}
+		if ch < '0' || ch > '9' {
+			return false
+		}
+
+		digit := int(ch - '0')
+		if digits%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		digits++
+	}
+	return digits >= 12 && digits <= 19 && sum%10 == 0
+}
+
+func (c CreditCard) DefaultMessage() string {
+	return fmt.Sprintln("Must be a valid credit card number")
+}
+
+func (c CreditCard) MessageKey() (string, []interface{}) {
+	return "validation.credit_card", nil
+}
+
+// Requires a value to be equal to one of the given values.
+// Values are compared with Equal.
+type OneOf struct {
+	Values []interface{}
+}
+
+func ValidOneOf(values ...interface{}) OneOf {
+	return OneOf{values}
+}
+
+func (o OneOf) IsSatisfied(obj interface{}) bool {
+	for _, value := range o.Values {
+		if Equal(obj, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o OneOf) DefaultMessage() string {
+	return fmt.Sprintln("Must be one of", o.valuesString())
+}
+
+func (o OneOf) MessageKey() (string, []interface{}) {
+	return "validation.one_of", []interface{}{o.valuesString()}
+}
+
+func (o OneOf) valuesString() string {
+	values := make([]string, len(o.Values))
+	for i, value := range o.Values {
+		values[i] = fmt.Sprint(value)
+	}
+	return strings.Join(values, ", ")
+}
+
+// Requires a value to be equal to another one, e.g. a password confirmation.
+// Name describes the other value in the message.
+type EqualTo struct {
+	Value interface{}
+	Name  string
+}
+
+func ValidEqualTo(value interface{}, name string) EqualTo {
+	return EqualTo{value, name}
+}
+
+func (e EqualTo) IsSatisfied(obj interface{}) bool {
+	return Equal(obj, e.Value)
+}
+
+func (e EqualTo) DefaultMessage() string {
+	return fmt.Sprintln("Must be equal to", e.Name)
+}
+
+func (e EqualTo) MessageKey() (string, []interface{}) {
+	return "validation.equal_to", []interface{}{e.Name}
+}
+
+// A ContextCheck adapts a function to a ContextValidator, e.g.
+//
+//   c.Validation.Check(user.Username, revel.ValidContext("Is already taken",
+//     func(c *revel.Controller, obj interface{}) (bool, error) {
+//       n, err := Dbm.SelectInt("select count(*) from User where Username=?", obj)
+//       return n == 0, err
+//     }))
+type ContextCheck struct {
+	Func    func(c *Controller, obj interface{}) (bool, error)
+	Message string
+}
+
+func ValidContext(message string, fn func(c *Controller, obj interface{}) (bool, error)) ContextCheck {
+	return ContextCheck{fn, message}
+}
+
+func (c ContextCheck) IsSatisfied(obj interface{}) bool {
+	ok, err := c.Func(nil, obj)
+	if err != nil {
+		panic(err)
+	}
+	return ok
+}
+
+func (c ContextCheck) IsSatisfiedContext(controller *Controller, obj interface{}) (bool, error) {
+	return c.Func(controller, obj)
+}
+
+func (c ContextCheck) DefaultMessage() string {
+	return c.Message
+}
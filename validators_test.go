@@ -253,3 +253,109 @@ func TestEmail(t *testing.T) {
 		}
 	}
 }
+
+func TestRangeFloat(t *testing.T) {
+	tests := []Expect{
+		Expect{1.5, true, "min < val < max"},
+		Expect{1.0, true, "val == min"},
+		Expect{2, true, "integer val == max"},
+		Expect{0.99, false, "val < min"},
+		Expect{float32(2.5), false, "val > max"},
+		Expect{"1.5", false, "TypeOf(val) is not a number"},
+	}
+	for _, r := range []RangeFloat{RangeFloat{MinFloat{1}, MaxFloat{2}}, ValidRangeFloat(1, 2)} {
+		performTests(r, tests, t)
+	}
+}
+
+func TestRangeTime(t *testing.T) {
+	min := time.Date(2014, time.January, 1, 0, 0, 0, 0, time.UTC)
+	max := min.AddDate(1, 0, 0)
+	tests := []Expect{
+		Expect{min.AddDate(0, 6, 0), true, "min < val < max"},
+		Expect{min, true, "val == min"},
+		Expect{max, true, "val == max"},
+		Expect{min.Add(-time.Second), false, "val < min"},
+		Expect{max.Add(time.Second), false, "val > max"},
+		Expect{"2014-06-01", false, "TypeOf(val) != time.Time"},
+	}
+	for _, r := range []RangeTime{RangeTime{MinTime{min}, MaxTime{max}}, ValidRangeTime(min, max)} {
+		performTests(r, tests, t)
+	}
+}
+
+func TestURL(t *testing.T) {
+	performTests(ValidURL(), []Expect{
+		Expect{"http://example.com/path?q=1", true, "http URL"},
+		Expect{"ftp://example.com", true, "ftp URL"},
+		Expect{"example.com", false, "no scheme"},
+		Expect{"http://", false, "no host"},
+		Expect{1, false, "TypeOf(val) != string"},
+	}, t)
+}
+
+func TestIPAddrAndCIDR(t *testing.T) {
+	performTests(ValidIPAddr(), []Expect{
+		Expect{"127.0.0.1", true, "IPv4"},
+		Expect{"::1", true, "IPv6"},
+		Expect{"256.0.0.1", false, "out of range IPv4"},
+		Expect{"10.0.0.0/8", false, "CIDR"},
+	}, t)
+	performTests(ValidCIDR(), []Expect{
+		Expect{"10.0.0.0/8", true, "IPv4 CIDR"},
+		Expect{"fd00::/8", true, "IPv6 CIDR"},
+		Expect{"10.0.0.1", false, "plain IP"},
+	}, t)
+}
+
+func TestUUID(t *testing.T) {
+	performTests(ValidUUID(), []Expect{
+		Expect{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", true, "UUID"},
+		Expect{"6BA7B810-9DAD-11D1-80B4-00C04FD430C8", true, "upper case UUID"},
+		Expect{"6ba7b8109dad11d180b400c04fd430c8", false, "no dashes"},
+		Expect{"6ba7b810-9dad-11d1-80b4-00c04fd430cz", false, "not hex"},
+	}, t)
+}
+
+func TestHostname(t *testing.T) {
+	performTests(ValidHostname(), []Expect{
+		Expect{"example.com", true, "domain"},
+		Expect{"localhost", true, "single label"},
+		Expect{"my-host.example.com.", true, "fully qualified"},
+		Expect{"-bad.example.com", false, "label starting with a dash"},
+		Expect{"bad_host.com", false, "underscore"},
+		Expect{strings.Repeat("a", 64) + ".com", false, "label too long"},
+	}, t)
+}
+
+func TestPhone(t *testing.T) {
+	performTests(ValidPhone(), []Expect{
+		Expect{"+14155552671", true, "E.164 number"},
+		Expect{"14155552671", false, "no plus sign"},
+		Expect{"+04155552671", false, "leading zero country code"},
+		Expect{"+1415555267123456", false, "too long"},
+	}, t)
+}
+
+func TestCreditCard(t *testing.T) {
+	performTests(ValidCreditCard(), []Expect{
+		Expect{"4111111111111111", true, "valid Visa test number"},
+		Expect{"4111 1111 1111 1111", true, "with spaces"},
+		Expect{"5500-0000-0000-0004", true, "with dashes"},
+		Expect{"4111111111111112", false, "bad checksum"},
+		Expect{"4111a11111111111", false, "letters"},
+		Expect{"0000", false, "too short"},
+	}, t)
+}
+
+func TestOneOfAndEqualTo(t *testing.T) {
+	performTests(ValidOneOf("red", "green", 3), []Expect{
+		Expect{"red", true, "listed string"},
+		Expect{int64(3), true, "listed number of another width"},
+		Expect{"blue", false, "unlisted string"},
+	}, t)
+	performTests(ValidEqualTo("secret", "Password"), []Expect{
+		Expect{"secret", true, "equal"},
+		Expect{"Secret", false, "not equal"},
+	}, t)
+}